@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+
+	"hypr-dock/pkg/dockIPC"
+)
+
+const usage = `usage: hypr-dock-ctl <command> [action] [data...] [--json]
+
+the arguments after "--" are sent as data, even if they look like options
+
+examples:
+  hypr-dock-ctl layer toggle
+  hypr-dock-ctl pinned list --json
+  hypr-dock-ctl item activate firefox
+
+run "hypr-dock-ctl help" to list the commands of the running dock
+`
+
+func main() {
+	args, useJSON, help := parseArgs(os.Args[1:])
+	if help || len(args) == 0 {
+		fmt.Print(usage)
+		os.Exit(dockIPC.CodeOK)
+	}
+
+	response, err := dockIPC.Send(dockIPC.SocketPath(), buildRequest(args, useJSON))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: Dock is not running")
+		os.Exit(dockIPC.CodeGeneral)
+	}
+
+	os.Exit(handleResponse(string(response), useJSON))
+}
+
+// parseArgs takes the options anywhere before "--",
+// the arguments after it are sent unchanged even if they start with a dash
+func parseArgs(args []string) (cmdParts []string, useJSON bool, help bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			return append(cmdParts, args[i+1:]...), useJSON, help
+		}
+
+		if !parseOption(arg, &useJSON, &help) {
+			cmdParts = append(cmdParts, arg)
+		}
+	}
+	return cmdParts, useJSON, help
+}
+
+func parseOption(arg string, useJSON *bool, help *bool) bool {
+	switch arg {
+	case "--json", "-j":
+		*useJSON = true
+	case "--help", "-h":
+		*help = true
+	default:
+		return false
+	}
+	return true
+}
+
+func buildRequest(parts []string, useJSON bool) string {
+	req := strings.Join(parts, " ")
+	if useJSON {
+		return "j/" + req
+	}
+	return req
+}
+
+var errorCode = regexp.MustCompile(`^error: .*\[(\d+)\]\s*$`)
+
+func handleResponse(response string, useJSON bool) int {
+	code := dockIPC.CodeOK
+
+	if useJSON {
+		var resp struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}
+		if json.Unmarshal([]byte(response), &resp) == nil && resp.Error != "" {
+			code = max(resp.Code, dockIPC.CodeGeneral)
+		}
+	} else if match := errorCode.FindStringSubmatch(response); match != nil {
+		code, _ = strconv.Atoi(match[1])
+	}
+
+	if code != dockIPC.CodeOK {
+		fmt.Fprint(os.Stderr, response)
+		return code
+	}
+
+	fmt.Print(response)
+	return code
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		parts   []string
+		useJSON bool
+		help    bool
+	}{
+		{"command", []string{"layer", "toggle"}, []string{"layer", "toggle"}, false, false},
+		{"leading json", []string{"--json", "pinned", "list"}, []string{"pinned", "list"}, true, false},
+		{"trailing json", []string{"pinned", "list", "--json"}, []string{"pinned", "list"}, true, false},
+		{"short json", []string{"window", "list", "-j"}, []string{"window", "list"}, true, false},
+		{"help", []string{"-h"}, nil, false, true},
+		{"trailing help", []string{"item", "--help"}, []string{"item"}, false, true},
+		{"escaped data", []string{"pinned", "toggle", "--", "--json"}, []string{"pinned", "toggle", "--json"}, false, false},
+		{"options before escape", []string{"-j", "item", "activate", "--", "-h", "--"}, []string{"item", "activate", "-h", "--"}, true, false},
+		{"escape only", []string{"--"}, nil, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts, useJSON, help := parseArgs(test.args)
+			if !slices.Equal(parts, test.parts) || useJSON != test.useJSON || help != test.help {
+				t.Fatalf("parseArgs(%q) = %q, %v, %v", test.args, parts, useJSON, help)
+			}
+		})
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	if request := buildRequest([]string{"pinned", "list"}, true); request != "j/pinned list" {
+		t.Fatalf("got %q", request)
+	}
+	if request := buildRequest([]string{"layer", "set", "smart"}, false); request != "layer set smart" {
+		t.Fatalf("got %q", request)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/go-hclog"
+
+	"hypr-dock/internal/pkg/flags"
+	"hypr-dock/internal/pkg/utils"
+	"hypr-dock/pkg/dockIPC"
+)
+
+// handoff forwards the launch flags to the already running instance
+// and returns the exit code of this one
+func handoff(flags flags.Flags, lockFilePath string, log hclog.Logger) int {
+	requests := handoffRequests(flags)
+	if len(requests) == 0 {
+		log.Info("hypr-dock is already running, use --toggle or --quit to close it")
+		return 0
+	}
+
+	code := 0
+	for _, request := range requests {
+		response, err := dockIPC.Send(dockIPC.SocketPath(), request)
+		if err != nil {
+			log.Error("Unable to reach the running dock", "socket", dockIPC.SocketPath(), "error", err)
+
+			if request == "quit" {
+				return killInstance(lockFilePath, log)
+			}
+			return 1
+		}
+
+		reply := strings.TrimSpace(string(response))
+		if strings.HasPrefix(reply, "error:") {
+			log.Error("The running dock rejected the request", "request", request, "reply", reply)
+			code = 1
+			continue
+		}
+
+		log.Info("Forwarded to the running dock", "request", request, "reply", reply)
+	}
+
+	return code
+}
+
+// handoffRequests returns the control requests matching the launch flags
+func handoffRequests(flags flags.Flags) []string {
+	if flags.Quit || flags.Toggle {
+		return []string{"quit"}
+	}
+
+	requests := []string{}
+	if flags.Config != "~/.config/hypr-dock" {
+		requests = append(requests, "config load "+absPath(flags.Config))
+	}
+	if flags.Theme != "" {
+		requests = append(requests, "config theme "+flags.Theme)
+	}
+	if flags.Reload {
+		requests = append(requests, "reload")
+	}
+	return requests
+}
+
+// killInstance stops a dock that holds the lock but has no control socket
+func killInstance(lockFilePath string, log hclog.Logger) int {
+	file, err := utils.LoadTextFile(lockFilePath)
+	if err != nil || len(file) == 0 {
+		log.Error("Unable to read the lock file", "file", lockFilePath, "error", err)
+		return 1
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(file[0]))
+	if err != nil {
+		log.Error("Invalid pid in the lock file", "file", lockFilePath, "error", err)
+		return 1
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		log.Error("Unable to stop the running dock", "pid", pid, "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+// absPath makes a relative config path usable from the running instance
+func absPath(path string) string {
+	if strings.HasPrefix(path, "~/") || filepath.IsAbs(path) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"hypr-dock/internal/pkg/flags"
+)
+
+func TestHandoffRequests(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := flags.Flags{Config: "~/.config/hypr-dock"}
+	with := func(change func(f *flags.Flags)) flags.Flags {
+		f := def
+		change(&f)
+		return f
+	}
+
+	tests := []struct {
+		name     string
+		flags    flags.Flags
+		requests []string
+	}{
+		{"no flags", def, []string{}},
+		{"reload", with(func(f *flags.Flags) { f.Reload = true }), []string{"reload"}},
+		{"quit", with(func(f *flags.Flags) { f.Quit = true; f.Reload = true }), []string{"quit"}},
+		{"toggle", with(func(f *flags.Flags) { f.Toggle = true }), []string{"quit"}},
+		{"theme", with(func(f *flags.Flags) { f.Theme = "lotos" }), []string{"config theme lotos"}},
+		{"home config", with(func(f *flags.Flags) { f.Config = "~/dock"; f.Reload = true }), []string{"config load ~/dock", "reload"}},
+		{"relative config", with(func(f *flags.Flags) { f.Config = "dock" }), []string{"config load " + filepath.Join(wd, "dock")}},
+	}
+
+	for _, test := range tests {
+		if requests := handoffRequests(test.flags); !slices.Equal(requests, test.requests) {
+			t.Errorf("%s: got %q, want %q", test.name, requests, test.requests)
+		}
+	}
+}
@@ -3,42 +3,42 @@ package main
 import (
 	"fmt"
 	"os"
-	"strconv"
-	"syscall"
+	"time"
 
 	"github.com/allan-simon/go-singleinstance"
 	"github.com/gotk3/gotk3/gtk"
 
 	"hypr-dock/internal/app"
+	"hypr-dock/internal/commands"
 	"hypr-dock/internal/hypr/hyprEvents"
-	"hypr-dock/internal/layering"
 	"hypr-dock/internal/pkg/flags"
 	"hypr-dock/internal/pkg/signals"
 	"hypr-dock/internal/pkg/utils"
 	"hypr-dock/internal/settings"
 	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc"
 )
 
 func main() {
-	signals.Handler()
+	// flags
+	flags := flags.Get()
+
+	logger := utils.СreateLogger(flags.LogLevel)
 
 	lockFilePath := fmt.Sprintf("%s/hypr-dock-%s.lock", utils.TempDir(), os.Getenv("USER"))
 	lockFile, err := singleinstance.CreateLockFile(lockFilePath)
 	if err != nil {
-		file, err := utils.LoadTextFile(lockFilePath)
-		if err == nil {
-			pidStr := file[0]
-			pidInt, _ := strconv.Atoi(pidStr)
-			syscall.Kill(pidInt, syscall.SIGUSR1)
-		}
-		os.Exit(0)
+		os.Exit(handoff(flags, lockFilePath, logger))
 	}
 	defer lockFile.Close()
 
-	// flags
-	flags := flags.Get()
+	if flags.Quit || flags.Reload {
+		logger.Info("hypr-dock is not running")
+		return
+	}
 
-	logger := utils.СreateLogger(flags.LogLevel)
+	signals.Handler()
 
 	// window build
 	settings, err := settings.Init(flags, logger)
@@ -50,31 +50,25 @@ func main() {
 
 	appState := state.New(settings, logger)
 
-	window, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
+	err = app.LoadTheme(appState)
 	if err != nil {
-		logger.Error("Unable to create window:", "err", err)
-		os.Exit(2)
+		logger.Warn("CSS file not found, the default GTK theme is running!", "err", err)
 	}
-	appState.SetWindow(window)
-
-	window.SetTitle("hypr-dock")
 
-	layerctl := layering.NewInit(window, settings)
-	appState.SetLayerctl(layerctl)
-
-	err = utils.AddCssProvider(settings.ThemeStyle)
+	err = ipc.StartCache(30 * time.Second)
 	if err != nil {
-		logger.Warn("CSS file not found, the default GTK theme is running!", "err", err)
+		logger.Error("Failed to load Hyprland state", "error", err)
 	}
 
-	app := app.BuildApp(appState)
-
-	window.Add(app)
-	window.Connect("destroy", func() { gtk.MainQuit() })
-	window.ShowAll()
+	// one dock, or one per monitor listed in Monitors
+	app.SyncDocks(appState)
 
 	// post
 	hyprEvents.Init(appState)
+	commands.Init(appState)
+	app.InitReload(appState)
+	defer dockIPC.StopServer(dockIPC.SocketPath())
+	defer dockIPC.StopServer(dockIPC.EventSocketPath())
 
 	// end
 	gtk.Main()
@@ -1,17 +1,20 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"slices"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 
 	"hypr-dock/internal/btnctl"
 	"hypr-dock/internal/hypr/hyprOpt"
 	"hypr-dock/internal/item"
-	"hypr-dock/internal/pkg/utils"
+	"hypr-dock/internal/pkg/grouping"
 	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
 	"hypr-dock/pkg/ipc"
 )
 
@@ -27,20 +30,15 @@ func BuildApp(appState *state.State) *gtk.Box {
 	}
 
 	initMargin(app, appState)
+	initDockDrop(app, appState)
 	app.SetName("app")
 
 	itemsBox, _ := gtk.BoxNew(orientation, settings.Spacing)
 	itemsBox.SetName("items-box")
 
-	switch orientation {
-	case gtk.ORIENTATION_HORIZONTAL:
-		itemsBox.SetMarginEnd(int(float64(settings.Spacing) * 0.8))
-		itemsBox.SetMarginStart(int(float64(settings.Spacing) * 0.8))
-	case gtk.ORIENTATION_VERTICAL:
-		itemsBox.SetMarginBottom(int(float64(settings.Spacing) * 0.8))
-		itemsBox.SetMarginTop(int(float64(settings.Spacing) * 0.8))
-	}
+	setItemsBoxMargin(itemsBox, orientation, settings.Spacing)
 
+	appState.SetAppBox(app)
 	appState.SetItemsBox(itemsBox)
 	renderItems(appState)
 	app.Add(itemsBox)
@@ -48,40 +46,77 @@ func BuildApp(appState *state.State) *gtk.Box {
 	return app
 }
 
+func setItemsBoxMargin(itemsBox *gtk.Box, orientation gtk.Orientation, spacing int) {
+	margin := int(float64(spacing) * 0.8)
+
+	switch orientation {
+	case gtk.ORIENTATION_HORIZONTAL:
+		itemsBox.SetMarginTop(0)
+		itemsBox.SetMarginBottom(0)
+		itemsBox.SetMarginEnd(margin)
+		itemsBox.SetMarginStart(margin)
+	case gtk.ORIENTATION_VERTICAL:
+		itemsBox.SetMarginEnd(0)
+		itemsBox.SetMarginStart(0)
+		itemsBox.SetMarginBottom(margin)
+		itemsBox.SetMarginTop(margin)
+	}
+}
+
 func renderItems(appState *state.State) {
-	clients, _ := ipc.GetClients()
+	clients := ipc.CachedClients()
 
 	for _, className := range *appState.GetPinned() {
 		InitNewItemInClass(className, appState)
 	}
 
+	if active, err := ipc.GetActiveWindow(); err == nil {
+		appState.SetActiveWindow(active.Address)
+	}
+
 	for _, ipcClient := range clients {
 		InitNewItemInIPC(ipcClient, appState)
 	}
 
+	sortItems(appState)
+
 	ipc.DispatchEvent("hd>>dock-render-finish")
+	dockIPC.SendEvent("renderfinish")
 }
 
 func InitNewItemInIPC(ipcClient ipc.Client, appState *state.State) {
-	list := appState.GetList()
-	className := ipcClient.Class
-
-	if className == "" {
-		className = utils.NormaliseTitle(ipcClient.InitialTitle)
+	if hiddenWindow(ipcClient, appState) {
+		return
 	}
 
-	pin := slices.Contains(*appState.GetPinned(), className)
-	added := list.Get(className) != nil
+	list := appState.GetList()
+	className := grouping.ClassName(ipcClient)
+	key := itemKey(ipcClient, appState)
 
-	if !pin && !added {
-		InitNewItemInClass(className, appState)
+	if list.Get(key) == nil {
+		initNewItem(className, key, appState)
 	}
 
-	list.Get(className).AddWindow(ipcClient)
+	item := list.Get(key)
+	if item == nil {
+		return
+	}
+	item.AddWindow(ipcClient)
+	if ipcClient.Address == appState.GetActiveWindow() {
+		item.SetFocused(ipcClient.Address)
+	}
+	sortItems(appState)
 	appState.GetWindow().ShowAll()
+
+	dockIPC.SendEvent("addwindow", className, ipcClient.Address)
 }
 
+// InitNewItemInClass adds the item of the class, the one a pinned app has in every GroupMode
 func InitNewItemInClass(className string, appState *state.State) {
+	initNewItem(className, className, appState)
+}
+
+func initNewItem(className string, key string, appState *state.State) {
 	log := appState.GetLogger()
 
 	list := appState.GetList()
@@ -92,13 +127,57 @@ func InitNewItemInClass(className string, appState *state.State) {
 	}
 
 	btnctl.Dispatch(item, appState)
+	initDrag(item, appState)
 
+	item.Key = key
 	item.List = list.GetMap()
 	item.PinnedList = appState.GetPinned()
-	list.Add(className, item)
+	item.Monitor = appState.GetMonitor()
+	item.OnPinChange(func(pinned bool) {
+		syncPinned(className, pinned, appState)
+	})
+	list.Add(key, item)
 
 	appState.GetItemsBox().Add(item.ButtonBox)
 	appState.GetWindow().ShowAll()
+
+	dockIPC.SendEvent("additem", className)
+}
+
+// RegroupWindow moves the window to the item of its current class
+// and returns true if it was moved. Some apps (Electron, Steam, Java)
+// set or change the class after the window is mapped.
+// With GroupMode = workspace the window also moves with its workspace.
+func RegroupWindow(client ipc.Client, appState *state.State) bool {
+	item, _, err := appState.GetList().SearchWindow(client.Address)
+	if err != nil {
+		return false
+	}
+
+	key := itemKey(client, appState)
+	if key == item.Key {
+		UpdateWindow(client, appState)
+		return false
+	}
+
+	appState.GetLogger().Debug("Window regrouped", "address", client.Address, "from", item.Key, "to", key)
+
+	urgent := slices.Contains(item.UrgentWindows(), client.Address)
+
+	RemoveApp(client.Address, appState)
+	InitNewItemInIPC(client, appState)
+
+	if urgent {
+		if item := appState.GetList().Get(key); item != nil {
+			item.SetUrgent(client.Address, true)
+		}
+	}
+
+	return true
+}
+
+func itemKey(client ipc.Client, appState *state.State) string {
+	return grouping.ItemKey(client, appState.GetSettings().GroupMode)
 }
 
 func RemoveApp(address string, appState *state.State) {
@@ -108,7 +187,9 @@ func RemoveApp(address string, appState *state.State) {
 	}
 
 	lastWindow := len(item.Windows) == 1
-	pin := slices.Contains(*appState.GetPinned(), item.ClassName)
+	pin := grouping.KeepsLauncher(item.Key, item.ClassName, *appState.GetPinned())
+
+	dockIPC.SendEvent("removewindow", item.ClassName, address)
 
 	if lastWindow && !pin {
 		item.Remove()
@@ -116,17 +197,152 @@ func RemoveApp(address string, appState *state.State) {
 	}
 
 	item.RemoveWindow(address)
+	sortItems(appState)
 
 	appState.GetWindow().ShowAll()
 }
 
+// PinApp pins the application in every dock
+func PinApp(className string, appState *state.State) error {
+	if slices.Contains(*appState.GetPinned(), className) {
+		return nil
+	}
+
+	docks := appState.Docks()
+	if len(docks) == 0 {
+		return errors.New("no dock is running")
+	}
+
+	// the other docks follow through OnPinChange
+	dock := docks[0]
+	list := dock.GetList()
+	if list.Get(className) == nil {
+		InitNewItemInClass(className, dock)
+	}
+
+	item := list.Get(className)
+	if item == nil {
+		return fmt.Errorf("unable to create item for %s", className)
+	}
+
+	item.TogglePin()
+	return nil
+}
+
+// UnpinApp unpins the application in every dock
+func UnpinApp(className string, appState *state.State) error {
+	for _, dock := range appState.Docks() {
+		item := dock.GetList().Get(className)
+		if item != nil && item.IsPinned() {
+			item.TogglePin()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not pinned", className)
+}
+
+// syncPinned adds the pinned item to the docks,
+// or removes the unpinned one where it has no windows
+func syncPinned(className string, pinned bool, appState *state.State) {
+	for _, dock := range appState.Docks() {
+		// with a GroupMode other than class the pinned item is not the one of the windows
+		item := dock.GetList().Get(className)
+		if pinned && item == nil {
+			InitNewItemInClass(className, dock)
+		}
+		if !pinned && item != nil && len(item.Windows) == 0 {
+			item.Remove()
+		}
+	}
+
+	// the item moves between the pinned and the running ones
+	for _, dock := range appState.Docks() {
+		sortItems(dock)
+	}
+}
+
 func ChangeWindowTitle(address string, title string, appState *state.State) {
-	_, client, err := appState.GetList().SearchWindow(address)
+	item, client, err := appState.GetList().SearchWindow(address)
 	if err != nil {
 		return
 	}
 
 	client.Title = title
+	item.UpdateTooltip()
+}
+
+// gap listeners of the docks
+var gapListeners = map[*state.State]*ipc.EventListener{}
+
+// UpdateWindow replaces the stored copy of the window with the current client,
+// which keeps the workspace, monitor, floating and fullscreen state accurate
+func UpdateWindow(client ipc.Client, appState *state.State) {
+	item, window, err := appState.GetList().SearchWindow(client.Address)
+	if err != nil {
+		// a hidden window is added when it leaves the scratchpad or comes to the dock monitor
+		if !hiddenWindow(client, appState) {
+			InitNewItemInIPC(client, appState)
+		}
+		return
+	}
+
+	if hiddenWindow(client, appState) {
+		RemoveApp(client.Address, appState)
+		return
+	}
+
+	// with GroupMode = workspace a window moved to another workspace changes its item
+	if itemKey(client, appState) != item.Key {
+		RegroupWindow(client, appState)
+		return
+	}
+
+	renamed := window.Title != client.Title
+	*window = client
+	item.UpdateSpecial()
+	if renamed {
+		item.UpdateTooltip()
+	}
+
+	if appState.GetSettings().ItemOrder == "workspace" {
+		sortItems(appState)
+	}
+}
+
+func hiddenWindow(client ipc.Client, appState *state.State) bool {
+	return grouping.Hidden(client, appState.GetSettings().General, dockMonitorID(appState))
+}
+
+// SetActiveWindow marks the item and the preview of the focused window
+func SetActiveWindow(address string, appState *state.State) {
+	appState.SetActiveWindow(address)
+
+	for _, item := range appState.GetList().GetMap() {
+		item.SetFocused(address)
+	}
+
+	appState.GetPV().SetFocused(address)
+
+	if appState.GetSettings().ItemOrder == "focus" {
+		sortItems(appState)
+	}
+}
+
+// SetUrgent marks the item of a window that demands attention,
+// the mark is cleared when the window is focused
+func SetUrgent(address string, appState *state.State) {
+	if address == appState.GetActiveWindow() {
+		return
+	}
+
+	item, _, err := appState.GetList().SearchWindow(address)
+	if err != nil {
+		return
+	}
+
+	item.SetUrgent(address, true)
+	dockIPC.SendEvent("urgent", item.ClassName, address)
 }
 
 func initMargin(app *gtk.Box, appState *state.State) {
@@ -136,6 +352,9 @@ func initMargin(app *gtk.Box, appState *state.State) {
 	position := settings.Position
 	defMargin := settings.Margin
 
+	// initMargin runs again on reload, the old listener keeps the old position
+	removeGapListener(appState)
+
 	if !settings.SystemGapUsed {
 		setMargin(app, position, defMargin)
 		return
@@ -150,10 +369,24 @@ func initMargin(app *gtk.Box, appState *state.State) {
 
 	setMargin(app, position, margin...)
 
-	hyprOpt.GapChangeEvent(func(gaps []int) {
-		setMargin(app, position, gaps...)
-		fmt.Println(gaps)
+	// the listener runs in the event goroutine, the dock and its settings belong to the main loop
+	listener := hyprOpt.GapChangeEvent(func(gaps []int) {
+		glib.IdleAdd(func() {
+			setMargin(app, position, gaps...)
+			appState.GetLogger().Debug("System gaps changed", "gaps", gaps)
+		})
 	})
+	// nil when the gaps can't be read
+	if listener != nil {
+		gapListeners[appState] = listener
+	}
+}
+
+func removeGapListener(appState *state.State) {
+	if listener, exist := gapListeners[appState]; exist {
+		listener.Remove()
+		delete(gapListeners, appState)
+	}
 }
 
 func setMargin(app *gtk.Box, position string, margin ...int) {
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"hypr-dock/internal/layering"
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc"
+)
+
+// how many times SyncDocks waits for gdk to see a new monitor
+const (
+	monitorRetries = 10
+	monitorRetryMs = 500
+)
+
+var (
+	errMonitorNotReady = errors.New("monitor is not known to gdk yet")
+	monitorRetry       int
+)
+
+// SyncDocks creates the docks wanted by the Monitors setting and removes the others.
+// Must be called from the gtk main loop.
+func SyncDocks(appState *state.State) {
+	log := appState.GetLogger()
+	wanted := dockMonitors(appState)
+
+	for _, dock := range appState.Docks() {
+		if !slices.Contains(wanted, dock.GetMonitor()) {
+			RemoveDock(dock)
+			log.Info("Dock removed", "monitor", dock.GetMonitor())
+		}
+	}
+
+	retry := false
+	for _, monitor := range wanted {
+		if appState.GetDock(monitor) != nil {
+			continue
+		}
+
+		_, err := NewDock(appState, monitor)
+		if errors.Is(err, errMonitorNotReady) {
+			retry = true
+			continue
+		}
+		if err != nil {
+			log.Error("Unable to create dock", "monitor", monitor, "error", err)
+			continue
+		}
+
+		log.Info("Dock created", "monitor", monitor)
+	}
+
+	if !retry {
+		monitorRetry = 0
+		followFocusedMonitor(appState)
+		return
+	}
+
+	// Hyprland reports a new monitor before gdk does
+	if monitorRetry < monitorRetries {
+		monitorRetry++
+		glib.TimeoutAdd(monitorRetryMs, func() bool {
+			SyncDocks(appState)
+			return false
+		})
+	} else {
+		log.Error("Unable to create docks, gdk does not see the monitors")
+		monitorRetry = 0
+	}
+}
+
+// NewDock creates the window of a dock on the monitor, "" lets the compositor choose
+func NewDock(appState *state.State, monitor string) (*state.State, error) {
+	var gdkMonitor *gdk.Monitor
+	if monitor != "" {
+		var err error
+		gdkMonitor, err = findGdkMonitor(monitor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	window, err := gtk.WindowNew(gtk.WINDOW_TOPLEVEL)
+	if err != nil {
+		return nil, err
+	}
+
+	dock := appState.NewDock(monitor)
+	dock.SetWindow(window)
+
+	window.SetTitle("hypr-dock")
+
+	// the dock geometry in j/layers changes without any Hyprland event
+	window.Connect("size-allocate", func() {
+		ipc.InvalidateLayers()
+	})
+
+	layerctl := layering.NewInit(window, appState.GetSettings())
+	if gdkMonitor != nil {
+		layerctl.SetMonitor(gdkMonitor)
+	}
+	dock.SetLayerctl(layerctl)
+
+	window.Add(BuildApp(dock))
+	window.ShowAll()
+
+	dockIPC.SendEvent("adddock", monitor)
+	return dock, nil
+}
+
+// RemoveDock destroys the window of the dock, e.g. after its monitor was removed
+func RemoveDock(dock *state.State) {
+	dock.RemoveDock()
+	removeGapListener(dock)
+
+	dock.GetPV().Hide()
+	dock.GetLayerctl().Destroy()
+	dock.GetWindow().Destroy()
+
+	dockIPC.SendEvent("removedock", dock.GetMonitor())
+}
+
+// dockMonitors returns the monitors that need a dock, "" stands for a single dock
+func dockMonitors(appState *state.State) []string {
+	var names []string
+	for _, name := range appState.GetSettings().Monitors {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return []string{""}
+	}
+
+	var monitors []string
+	for _, monitor := range ipc.CachedMonitors() {
+		if slices.Contains(names, "all") || slices.Contains(names, monitor.Name) {
+			monitors = append(monitors, monitor.Name)
+		}
+	}
+
+	return monitors
+}
+
+// findGdkMonitor matches the Hyprland monitor with a gdk monitor by position,
+// gtk3 does not expose the connector name
+func findGdkMonitor(name string) (*gdk.Monitor, error) {
+	hyprMonitor, exist := ipc.CachedMonitor(name)
+	if !exist {
+		return nil, fmt.Errorf("monitor %s not found", name)
+	}
+
+	display, err := gdk.DisplayGetDefault()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < display.GetNMonitors(); i++ {
+		monitor, err := display.GetMonitor(i)
+		if err != nil || monitor == nil {
+			continue
+		}
+
+		geometry := monitor.GetGeometry()
+		if geometry.GetX() == hyprMonitor.X && geometry.GetY() == hyprMonitor.Y {
+			return monitor, nil
+		}
+	}
+
+	return nil, errMonitorNotReady
+}
+
+// FollowMonitor moves the single dock to the monitor if FollowMonitor is set.
+// Must be called from the gtk main loop.
+func FollowMonitor(monitor string, appState *state.State) {
+	settings := appState.GetSettings()
+	log := appState.GetLogger()
+
+	dock := appState.GetDock("")
+	if !settings.FollowMonitor || dock == nil || dock.GetFollowedMonitor() == monitor {
+		return
+	}
+
+	gdkMonitor, err := findGdkMonitor(monitor)
+	if err != nil {
+		log.Debug("Unable to move the dock", "monitor", monitor, "error", err)
+		return
+	}
+
+	// the preview and the menus are placed by the old dock position
+	dock.GetPV().Hide()
+
+	dock.SetFollowedMonitor(monitor)
+	dock.GetLayerctl().SetMonitor(gdkMonitor)
+	ipc.InvalidateLayers()
+
+	if settings.MonitorWindows == "own" {
+		Resync(ipc.CachedClients(), dock)
+	}
+
+	dockIPC.SendEvent("movedock", monitor)
+	log.Debug("Dock moved", "monitor", monitor)
+}
+
+func followFocusedMonitor(appState *state.State) {
+	for _, monitor := range ipc.CachedMonitors() {
+		if monitor.Focused {
+			FollowMonitor(monitor.Name, appState)
+			return
+		}
+	}
+}
+
+// dockMonitorID returns the Hyprland id of the dock monitor,
+// -1 for a single dock that does not follow the focus
+func dockMonitorID(appState *state.State) int {
+	monitor := appState.GetMonitor()
+	if monitor == "" {
+		monitor = appState.GetFollowedMonitor()
+	}
+	if monitor == "" {
+		return -1
+	}
+
+	hyprMonitor, exist := ipc.CachedMonitor(monitor)
+	if !exist {
+		return -1
+	}
+	return hyprMonitor.Id
+}
+
+// FocusedDock returns the dock on the focused monitor, or the first one
+func FocusedDock(appState *state.State) *state.State {
+	docks := appState.Docks()
+	if len(docks) == 0 {
+		return nil
+	}
+
+	for _, monitor := range ipc.CachedMonitors() {
+		if !monitor.Focused {
+			continue
+		}
+		if dock := appState.GetDock(monitor.Name); dock != nil {
+			return dock
+		}
+	}
+
+	return docks[0]
+}
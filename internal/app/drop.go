@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"hypr-dock/internal/desktop"
+	"hypr-dock/internal/item"
+	"hypr-dock/internal/pkg/timer"
+	"hypr-dock/internal/state"
+)
+
+// files and URIs dragged from other apps
+const uriTarget = "text/uri-list"
+
+// how long a file is held over an item before its window comes forward
+const springDelay = 700
+
+var (
+	springTimer = timer.New()
+	// key of the item the file is held over
+	springKey string
+)
+
+// initDockDrop pins the apps of the desktop files dropped next to the items
+func initDockDrop(app *gtk.Box, appState *state.State) {
+	target, err := gtk.TargetEntryNew(uriTarget, gtk.TARGET_OTHER_APP, uriInfo)
+	if err != nil {
+		appState.GetLogger().Error("Unable to create drag target", "error", err)
+		return
+	}
+
+	app.DragDestSet(gtk.DEST_DEFAULT_ALL, []gtk.TargetEntry{*target}, gdk.ACTION_COPY)
+	app.Connect("drag-data-received", func(_ *gtk.Box, _ *gdk.DragContext, _, _ int, data *gtk.SelectionData) {
+		DropFiles(data.GetURIs(), nil, appState)
+	})
+}
+
+// DropFiles pins the apps of the dropped desktop files and opens the other files
+// with the app of the item, item is nil for a drop outside of the items
+func DropFiles(uris []string, item *item.Item, appState *state.State) {
+	log := appState.GetLogger()
+
+	var files []string
+	for _, uri := range uris {
+		path, ok := desktopFilePath(uri)
+		if !ok {
+			files = append(files, uri)
+			continue
+		}
+
+		className, err := desktopClassName(path)
+		if err != nil {
+			log.Error("Unable to pin the dropped app", "file", path, "error", err)
+			continue
+		}
+
+		if err := PinApp(className, appState); err != nil {
+			log.Error("Unable to pin the dropped app", "file", path, "error", err)
+		}
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	if item == nil {
+		log.Debug("Files dropped outside of the items", "files", files)
+		return
+	}
+
+	item.Open(files)
+}
+
+// desktopFilePath returns the path of a local .desktop file
+func desktopFilePath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || !strings.HasSuffix(u.Path, ".desktop") {
+		return "", false
+	}
+	return u.Path, true
+}
+
+// desktopClassName returns the class of a desktop file the dock is able to find again,
+// a file outside of the application directories would lose its name and icon
+func desktopClassName(path string) (string, error) {
+	className, err := desktop.ClassName(path)
+	if err != nil {
+		return "", err
+	}
+
+	if desktop.SearchDesktopFile(className) == "" {
+		return "", fmt.Errorf("%s is not in the application directories", path)
+	}
+	return className, nil
+}
+
+// initSpring brings the windows of the item forward while a file is held over it,
+// so that the file can be dropped into a window
+func initSpring(item *item.Item, appState *state.State) {
+	button := item.Button
+
+	button.Connect("drag-motion", func() bool {
+		if draggingItem || len(item.Windows) == 0 || springKey == item.Key {
+			return false
+		}
+		springKey = item.Key
+
+		springTimer.Run(springDelay, func() {
+			glib.IdleAdd(func() {
+				spring(item, appState)
+			})
+		})
+		return false
+	})
+
+	button.Connect("drag-leave", func() {
+		springTimer.Stop()
+		springKey = ""
+	})
+}
+
+// spring focuses the single window of the item, several windows are shown in the preview
+func spring(item *item.Item, appState *state.State) {
+	pv := appState.GetPV()
+	if len(item.Windows) > 1 && appState.GetSettings().Preview.Mode != "none" {
+		if pv.GetActive() {
+			pv.Change(item)
+		} else {
+			pv.Show(item)
+		}
+		return
+	}
+
+	if window, ok := item.RecentWindow(); ok {
+		item.FocusWindow(window.Address)
+	}
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"hypr-dock/internal/item"
+	"hypr-dock/internal/pkg/signals"
+	"hypr-dock/internal/pkg/utils"
+	"hypr-dock/internal/pkg/watcher"
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc"
+)
+
+var (
+	themeProvider *gtk.CssProvider
+	configWatcher *watcher.Watcher
+)
+
+// LoadTheme replaces the style.css of the previous theme with the current one
+func LoadTheme(appState *state.State) error {
+	if themeProvider != nil {
+		utils.RemoveCssProvider(themeProvider)
+		themeProvider = nil
+	}
+
+	provider, err := utils.AddCssProvider(appState.GetSettings().ThemeStyle)
+	if err != nil {
+		return err
+	}
+
+	themeProvider = provider
+	return nil
+}
+
+// InitReload reloads the dock on SIGHUP and, if AutoReload is set,
+// when the config, theme.conf or style.css is changed
+func InitReload(appState *state.State) {
+	reload := func() {
+		Reload(appState)
+	}
+
+	signals.OnReload(reload)
+
+	if !appState.GetSettings().AutoReload {
+		return
+	}
+
+	configWatcher = watcher.New(time.Second, func() {
+		glib.IdleAdd(reload)
+	}, watchedFiles(appState)...)
+}
+
+// Reload re-reads the config and the theme and applies them to the running docks.
+// Must be called from the gtk main loop.
+func Reload(appState *state.State) error {
+	log := appState.GetLogger()
+	settings := appState.GetSettings()
+
+	err := settings.Reload(log)
+	if err != nil {
+		log.Error("Failed to reload config, keeping the previous one", "error", err)
+		return err
+	}
+
+	if configWatcher != nil {
+		configWatcher.SetPaths(watchedFiles(appState)...)
+	}
+
+	err = LoadTheme(appState)
+	if err != nil {
+		log.Warn("CSS file not found, the default GTK theme is running!", "err", err)
+	}
+
+	ipc.InvalidateLayers()
+	for _, dock := range appState.Docks() {
+		reloadDock(dock)
+	}
+
+	// Monitors may have changed
+	SyncDocks(appState)
+
+	dockIPC.SendEvent("reload")
+	log.Info("Config reloaded", "config", settings.ConfigPath, "theme", settings.CurrentTheme)
+
+	return nil
+}
+
+func reloadDock(dock *state.State) {
+	settings := dock.GetSettings()
+
+	dock.GetPV().Hide()
+
+	layerctl := dock.GetLayerctl()
+	layerctl.Reload()
+	orientation := layerctl.GetOrientation()
+
+	app := dock.GetAppBox()
+	app.SetOrientation(orientation)
+	app.SetMarginTop(0)
+	app.SetMarginEnd(0)
+	app.SetMarginBottom(0)
+	app.SetMarginStart(0)
+	initMargin(app, dock)
+
+	itemsBox := dock.GetItemsBox()
+	itemsBox.SetOrientation(orientation)
+	itemsBox.SetSpacing(settings.Spacing)
+	setItemsBoxMargin(itemsBox, orientation, settings.Spacing)
+
+	rebuildItems(dock)
+	sortItems(dock)
+	// SpecialWindows and MonitorWindows may have changed, which hides or shows windows
+	Resync(ipc.CachedClients(), dock)
+
+	dock.GetWindow().ShowAll()
+}
+
+// rebuildItems re-creates every item in its current order,
+// so that icon size, position and preview mode are applied
+func rebuildItems(appState *state.State) {
+	for _, old := range orderedItems(appState) {
+		className := old.ClassName
+		key := old.Key
+
+		var windows []ipc.Client
+		for _, window := range old.Windows {
+			windows = append(windows, *window)
+		}
+		sort.Slice(windows, func(i, j int) bool {
+			return windows[i].Address < windows[j].Address
+		})
+
+		old.Remove()
+		initNewItem(className, key, appState)
+
+		newItem := appState.GetList().Get(key)
+		if newItem == nil {
+			continue
+		}
+
+		for _, window := range windows {
+			newItem.AddWindow(window)
+		}
+		newItem.SetFocused(appState.GetActiveWindow())
+		for _, address := range old.UrgentWindows() {
+			newItem.SetUrgent(address, true)
+		}
+	}
+}
+
+func orderedItems(appState *state.State) []*item.Item {
+	list := appState.GetList()
+	children := appState.GetItemsBox().GetChildren()
+
+	var items []*item.Item
+	children.Foreach(func(child interface{}) {
+		widget, ok := child.(*gtk.Widget)
+		if !ok {
+			return
+		}
+
+		for _, item := range list.GetMap() {
+			if item.ButtonBox.Native() == widget.Native() {
+				items = append(items, item)
+				return
+			}
+		}
+	})
+
+	return items
+}
+
+func watchedFiles(appState *state.State) []string {
+	settings := appState.GetSettings()
+	return []string{settings.ConfigPath, settings.ThemeConf, settings.ThemeStyle}
+}
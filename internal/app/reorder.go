@@ -0,0 +1,184 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/gtk"
+
+	"hypr-dock/internal/item"
+	"hypr-dock/internal/pkg/pinned"
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+)
+
+// target of an item dragged within the dock, the data is its class
+const itemTarget = "hypr-dock/item"
+
+// info of the drag targets
+const (
+	itemInfo uint = iota
+	uriInfo
+)
+
+// an item is being dragged, other drags bring the windows forward
+var draggingItem bool
+
+// initDrag lets the item be dragged onto another item to take its place
+// and accepts files dropped from other apps
+func initDrag(item *item.Item, appState *state.State) {
+	log := appState.GetLogger()
+
+	target, err := gtk.TargetEntryNew(itemTarget, gtk.TARGET_SAME_APP, itemInfo)
+	if err != nil {
+		log.Error("Unable to create drag target", "error", err)
+		return
+	}
+	uris, err := gtk.TargetEntryNew(uriTarget, gtk.TARGET_OTHER_APP, uriInfo)
+	if err != nil {
+		log.Error("Unable to create drag target", "error", err)
+		return
+	}
+
+	button := item.Button
+	button.DragSourceSet(gdk.BUTTON1_MASK, []gtk.TargetEntry{*target}, gdk.ACTION_MOVE)
+	button.DragDestSet(gtk.DEST_DEFAULT_ALL, []gtk.TargetEntry{*target, *uris}, gdk.ACTION_MOVE|gdk.ACTION_COPY)
+
+	button.Connect("drag-begin", func(_ *gtk.Button, context *gdk.DragContext) {
+		draggingItem = true
+		appState.GetPV().Hide()
+		setDragIcon(context, item.App.GetIcon(), appState.GetSettings().IconSize)
+	})
+
+	button.Connect("drag-end", func() {
+		draggingItem = false
+	})
+
+	button.Connect("drag-data-get", func(_ *gtk.Button, _ *gdk.DragContext, data *gtk.SelectionData) {
+		data.SetData(gdk.GdkAtomIntern(itemTarget, false), []byte(item.Key))
+	})
+
+	button.Connect("drag-data-received", func(_ *gtk.Button, _ *gdk.DragContext, _, _ int, data *gtk.SelectionData, info uint) {
+		switch info {
+		case itemInfo:
+			MoveItem(string(data.GetData()), item.Key, appState)
+		case uriInfo:
+			DropFiles(data.GetURIs(), item, appState)
+		}
+	})
+
+	initSpring(item, appState)
+}
+
+// setDragIcon shows the app icon under the cursor, the icon is a name or a file like in item.New
+func setDragIcon(context *gdk.DragContext, icon string, size int) {
+	if !strings.Contains(icon, "/") {
+		gtk.DragSetIconName(context, icon, size/2, size/2)
+		return
+	}
+
+	pixbuf, err := gdk.PixbufNewFromFileAtSize(icon, size, size)
+	if err != nil {
+		gtk.DragSetIconDefault(context)
+		return
+	}
+	gtk.DragSetIconPixbuf(context, pixbuf, size/2, size/2)
+}
+
+// MoveItem puts the source item in place of the target item (both are item keys)
+// in every dock and saves the new order of the pinned apps
+func MoveItem(source string, target string, appState *state.State) {
+	if source == "" || source == target {
+		return
+	}
+
+	sourceItem := appState.GetList().Get(source)
+	if sourceItem == nil || appState.GetList().Get(target) == nil {
+		return
+	}
+
+	if appState.GetSettings().PinOnDrag && !sourceItem.IsPinned() {
+		sourceItem.TogglePin()
+	}
+
+	for _, dock := range appState.Docks() {
+		moveItem(source, target, dock)
+	}
+
+	savePinnedOrder(appState)
+
+	// with an ItemOrder other than launch only the pinned apps keep the new place
+	for _, dock := range appState.Docks() {
+		sortItems(dock)
+	}
+
+	dockIPC.SendEvent("moveitem", source, target)
+	appState.GetLogger().Debug("Item moved", "item", source, "to", target)
+}
+
+func moveItem(source string, target string, appState *state.State) {
+	list := appState.GetList()
+	sourceItem := list.Get(source)
+	targetItem := list.Get(target)
+	if sourceItem == nil || targetItem == nil {
+		return
+	}
+
+	position := slices.Index(orderedItems(appState), targetItem)
+	if position < 0 {
+		return
+	}
+
+	appState.GetItemsBox().ReorderChild(sourceItem.ButtonBox, position)
+}
+
+// savePinnedOrder writes the pinned apps in the order of the dock items,
+// so that the dock starts with the same order
+func savePinnedOrder(appState *state.State) {
+	list := appState.GetPinned()
+
+	var order []string
+	for _, item := range orderedItems(appState) {
+		if item.Key == item.ClassName && slices.Contains(*list, item.ClassName) {
+			order = append(order, item.ClassName)
+		}
+	}
+
+	// pinned apps without an item keep their place at the end
+	for _, className := range *list {
+		if !slices.Contains(order, className) {
+			order = append(order, className)
+		}
+	}
+
+	if slices.Equal(order, *list) {
+		return
+	}
+
+	*list = order
+
+	file := appState.GetSettings().PinnedPath
+	if err := pinned.Save(file, order); err != nil {
+		appState.GetLogger().Error("Failed to save pinned list", "file", file, "error", err)
+	}
+}
+
+// sortItems puts the items in the order of ItemOrder,
+// "launch" keeps the order they were added or dragged to
+func sortItems(appState *state.State) {
+	settings := appState.GetSettings()
+	if settings.ItemOrder == "launch" {
+		return
+	}
+
+	sorted := appState.GetList().Sorted(settings.ItemOrder, *appState.GetPinned())
+	if slices.Equal(sorted, orderedItems(appState)) {
+		return
+	}
+
+	itemsBox := appState.GetItemsBox()
+	for position, item := range sorted {
+		itemsBox.ReorderChild(item.ButtonBox, position)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc"
+)
+
+type ResyncResult struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Renamed   int `json:"renamed"`
+	Regrouped int `json:"regrouped"`
+}
+
+// Resync adds the windows missing in the dock, removes the ones that no longer
+// exist, moves the ones whose class changed and updates the rest, e.g. after events were lost while disconnected.
+// Must be called from the gtk main loop.
+func Resync(clients []ipc.Client, appState *state.State) ResyncResult {
+	var result ResyncResult
+	list := appState.GetList()
+
+	current := make(map[string]ipc.Client, len(clients))
+	for _, client := range clients {
+		current[client.Address] = client
+	}
+
+	var removed []string
+	var existing []ipc.Client
+	for _, item := range list.GetMap() {
+		for address, window := range item.Windows {
+			client, exist := current[address]
+			if !exist {
+				removed = append(removed, address)
+				continue
+			}
+
+			if client.Title != window.Title {
+				result.Renamed++
+			}
+			existing = append(existing, client)
+		}
+	}
+
+	for _, address := range removed {
+		RemoveApp(address, appState)
+		result.Removed++
+	}
+
+	// regrouping changes the items, so it is not done while iterating them
+	for _, client := range existing {
+		if RegroupWindow(client, appState) {
+			result.Regrouped++
+		}
+	}
+
+	for _, client := range clients {
+		if _, _, err := list.SearchWindow(client.Address); err == nil || hiddenWindow(client, appState) {
+			continue
+		}
+
+		InitNewItemInIPC(client, appState)
+		result.Added++
+	}
+
+	appState.GetLogger().Debug("Dock resynced", "added", result.Added, "removed", result.Removed, "renamed", result.Renamed, "regrouped", result.Regrouped)
+	if result.Added > 0 || result.Removed > 0 || result.Regrouped > 0 {
+		appState.GetWindow().ShowAll()
+	}
+	dockIPC.SendEvent("resync")
+
+	return result
+}
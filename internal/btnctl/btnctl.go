@@ -5,7 +5,6 @@ import (
 	"hypr-dock/internal/item"
 	"hypr-dock/internal/pkg/utils"
 	"hypr-dock/internal/state"
-	"hypr-dock/pkg/ipc"
 
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/gtk"
@@ -15,6 +14,16 @@ func Dispatch(item *item.Item, appState *state.State) {
 	settings := appState.GetSettings()
 	ctrl := defaultcontrol.New(item, settings, appState.GetLogger())
 
+	// with GroupMode workspace or none the windows of a pinned app are in other items,
+	// its launcher brings them forward
+	ctrl.ResetZero(func() {
+		if address, ok := recentWindow(item.ClassName, appState); ok {
+			item.FocusWindow(address)
+			return
+		}
+		item.Launch()
+	})
+
 	// preview
 	if appState.GetSettings().Preview.Mode != "none" {
 		previewControl(item, ctrl, appState)
@@ -29,6 +38,19 @@ func Dispatch(item *item.Item, appState *state.State) {
 	ctrl.Init()
 }
 
+// recentWindow returns the window of the class that was focused last in the items of the dock
+func recentWindow(className string, appState *state.State) (string, bool) {
+	var items []*item.Item
+	for _, classItem := range appState.GetList().GetMap() {
+		if classItem.ClassName == className {
+			items = append(items, classItem)
+		}
+	}
+
+	window, ok := item.MostRecent(items...)
+	return window.Address, ok
+}
+
 func previewControl(item *item.Item, ctrl *defaultcontrol.Control, appState *state.State) {
 	pv := appState.GetPV()
 	showTimer := pv.GetShowTimer()
@@ -37,7 +59,7 @@ func previewControl(item *item.Item, ctrl *defaultcontrol.Control, appState *sta
 	ctrl.ResetSingle(func() {
 		client, ok := utils.GetSingleValue(item.Windows)
 		if ok {
-			ipc.Hyprctl("dispatch focuswindow address:" + client.Address)
+			item.FocusWindow(client.Address)
 
 			showTimer.Stop()
 			if pv.GetActive() {
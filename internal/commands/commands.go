@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"sort"
+
+	"github.com/gotk3/gotk3/glib"
+
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+)
+
+type Action struct {
+	Handler     func(data string) (interface{}, error)
+	NeedsData   bool
+	Usage       string
+	Description string
+}
+
+type Command struct {
+	Description string
+	Actions     map[string]Action
+	Default     *Action
+}
+
+// Init starts the control socket and the event stream socket of the dock
+func Init(appState *state.State) {
+	log := appState.GetLogger()
+	commands := build(appState)
+	socket := dockIPC.SocketPath()
+	eventSocket := dockIPC.EventSocketPath()
+
+	go func() {
+		log.Debug("Control socket started", "path", socket)
+
+		err := dockIPC.StartServer(socket, func(request string) ([]byte, error) {
+			log.Debug("Control request", "request", request)
+			return handle(request, commands), nil
+		})
+		if err != nil {
+			log.Error("Control socket failed", "path", socket, "error", err)
+		}
+	}()
+
+	go func() {
+		log.Debug("Event socket started", "path", eventSocket)
+
+		err := dockIPC.StartEventServer(eventSocket)
+		if err != nil {
+			log.Error("Event socket failed", "path", eventSocket, "error", err)
+		}
+	}()
+}
+
+func handle(request string, commands map[string]Command) []byte {
+	command, action, data, isJSON := dockIPC.ParseRequest(request)
+
+	// all handlers touch gtk widgets, so run them in the main loop
+	done := make(chan []byte, 1)
+	glib.IdleAdd(func() {
+		result, err := dispatch(commands, command, action, data)
+		done <- dockIPC.Reply(result, err, isJSON)
+	})
+
+	return <-done
+}
+
+func dispatch(commands map[string]Command, command string, action string, data string) (interface{}, error) {
+	if command == "" {
+		return nil, dockIPC.NewError(dockIPC.CodeArgs, "empty request")
+	}
+
+	cmd, exist := commands[command]
+	if !exist {
+		return nil, dockIPC.NewError(dockIPC.CodeArgs, "unknown command %q", command)
+	}
+
+	act, exist := cmd.Actions[action]
+	if !exist {
+		if action != "" || cmd.Default == nil {
+			return nil, dockIPC.NewError(dockIPC.CodeArgs, "unknown action %q for %q", action, command)
+		}
+		act = *cmd.Default
+	}
+
+	if act.NeedsData && data == "" {
+		return nil, dockIPC.NewError(dockIPC.CodeArgs, "usage: %s %s", command, act.Usage)
+	}
+
+	return act.Handler(data)
+}
+
+func build(appState *state.State) map[string]Command {
+	commands := map[string]Command{
+		"item":   itemCommand(appState),
+		"window": windowCommand(appState),
+		"pinned": pinnedCommand(appState),
+		"layer":  layerCommand(appState),
+		"reload": reloadCommand(appState),
+		"resync": resyncCommand(appState),
+		"config": configCommand(appState),
+		"quit":   quitCommand(),
+		"events": eventsCommand(),
+	}
+
+	help := Action{
+		Handler: func(data string) (interface{}, error) {
+			return usage(commands), nil
+		},
+		Description: "list available commands",
+	}
+	commands["help"] = Command{
+		Description: "show this help",
+		Actions:     map[string]Action{},
+		Default:     &help,
+	}
+
+	return commands
+}
+
+type actionUsage struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage"`
+	Description string `json:"description"`
+}
+
+type commandUsage struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Actions     []actionUsage `json:"actions"`
+}
+
+func usage(commands map[string]Command) []commandUsage {
+	var result []commandUsage
+
+	for name, cmd := range commands {
+		cu := commandUsage{
+			Name:        name,
+			Description: cmd.Description,
+			Actions:     []actionUsage{},
+		}
+
+		for actName, act := range cmd.Actions {
+			cu.Actions = append(cu.Actions, actionUsage{
+				Name:        actName,
+				Usage:       act.Usage,
+				Description: act.Description,
+			})
+		}
+
+		sort.Slice(cu.Actions, func(i, j int) bool {
+			return cu.Actions[i].Name < cu.Actions[j].Name
+		})
+		result = append(result, cu)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,679 @@
+package commands
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"hypr-dock/internal/app"
+	"hypr-dock/internal/desktop"
+	"hypr-dock/internal/item"
+	"hypr-dock/internal/layering"
+	"hypr-dock/internal/state"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc"
+	hyprdispatch "hypr-dock/pkg/ipc/dispatch"
+)
+
+type windowInfo struct {
+	Address   string `json:"address"`
+	Class     string `json:"class"`
+	Title     string `json:"title"`
+	Workspace string `json:"workspace"`
+	Monitor   int    `json:"monitor"`
+}
+
+type itemInfo struct {
+	Class   string       `json:"class"`
+	Name    string       `json:"name"`
+	Pinned  bool         `json:"pinned"`
+	Windows []windowInfo `json:"windows"`
+}
+
+type layerInfo struct {
+	Mode     string            `json:"mode"`
+	Layer    string            `json:"layer"`
+	Visible  bool              `json:"visible"`
+	Special  bool              `json:"special"`
+	Specials map[string]string `json:"specials"`
+	Position string            `json:"position"`
+}
+
+func itemCommand(appState *state.State) Command {
+	list := Action{
+		Handler: func(data string) (interface{}, error) {
+			return itemInfos(appState), nil
+		},
+		Usage:       "list",
+		Description: "list dock items",
+	}
+
+	return Command{
+		Description: "dock items",
+		Default:     &list,
+		Actions: map[string]Action{
+			"list": list,
+			"get": {
+				Handler: func(data string) (interface{}, error) {
+					for _, info := range itemInfos(appState) {
+						if info.Class == data {
+							return info, nil
+						}
+					}
+					return nil, dockIPC.NewError(dockIPC.CodeNotFound, "item not found: %s", data)
+				},
+				NeedsData:   true,
+				Usage:       "get <class>",
+				Description: "show a dock item",
+			},
+			"activate": {
+				Handler: func(data string) (interface{}, error) {
+					items, err := findItems(data, appState)
+					if err == nil {
+						return activate(items)
+					}
+
+					// an app that is neither pinned nor running is launched from its desktop entry
+					if desktop.SearchDesktopFile(data) == "" {
+						return nil, err
+					}
+					return launchDesktop(data)
+				},
+				NeedsData:   true,
+				Usage:       "activate <class>",
+				Description: "focus the next window of an app or launch it",
+			},
+			"launch": {
+				Handler: func(data string) (interface{}, error) {
+					if items, err := findItems(data, appState); err == nil {
+						items[0].Launch()
+						return dockIPC.Message("launched " + data), nil
+					}
+
+					return launchDesktop(data)
+				},
+				NeedsData:   true,
+				Usage:       "launch <class>",
+				Description: "start a new instance of an application",
+			},
+		},
+	}
+}
+
+func windowCommand(appState *state.State) Command {
+	list := Action{
+		Handler: func(data string) (interface{}, error) {
+			windows := []windowInfo{}
+			for _, info := range itemInfos(appState) {
+				windows = append(windows, info.Windows...)
+			}
+			return windows, nil
+		},
+		Usage:       "list",
+		Description: "list windows tracked by the dock",
+	}
+
+	return Command{
+		Description: "windows tracked by the dock",
+		Default:     &list,
+		Actions: map[string]Action{
+			"list": list,
+			"focus": {
+				Handler: func(data string) (interface{}, error) {
+					return windowDispatch(hyprdispatch.FocusWindow(data), data, appState)
+				},
+				NeedsData:   true,
+				Usage:       "focus <address>",
+				Description: "focus a window",
+			},
+			"close": {
+				Handler: func(data string) (interface{}, error) {
+					return windowDispatch(hyprdispatch.CloseWindow(data), data, appState)
+				},
+				NeedsData:   true,
+				Usage:       "close <address>",
+				Description: "close a window",
+			},
+			"scratchpad": {
+				Handler: func(data string) (interface{}, error) {
+					_, window, err := searchWindow(data, appState)
+					if err != nil {
+						return nil, dockIPC.NewError(dockIPC.CodeNotFound, "window %q not found", data)
+					}
+
+					name, ok := ipc.SpecialWorkspaceName(window.Workspace.Name)
+					if !ok {
+						return nil, dockIPC.NewError(dockIPC.CodeArgs, "window %q is not on a special workspace", data)
+					}
+					return windowDispatch(hyprdispatch.ToggleSpecialWorkspace(name), data, appState)
+				},
+				NeedsData:   true,
+				Usage:       "scratchpad <address>",
+				Description: "show or hide the special workspace of a window",
+			},
+		},
+	}
+}
+
+func pinnedCommand(appState *state.State) Command {
+	list := Action{
+		Handler: func(data string) (interface{}, error) {
+			return append([]string{}, *appState.GetPinned()...), nil
+		},
+		Usage:       "list",
+		Description: "list pinned applications",
+	}
+
+	return Command{
+		Description: "pinned applications",
+		Default:     &list,
+		Actions: map[string]Action{
+			"list": list,
+			"add": {
+				Handler: func(data string) (interface{}, error) {
+					if err := app.PinApp(data, appState); err != nil {
+						return nil, err
+					}
+					return dockIPC.Message("pinned " + data), nil
+				},
+				NeedsData:   true,
+				Usage:       "add <class>",
+				Description: "pin an application",
+			},
+			"remove": {
+				Handler: func(data string) (interface{}, error) {
+					if err := app.UnpinApp(data, appState); err != nil {
+						return nil, dockIPC.NewError(dockIPC.CodeNotFound, "%v", err)
+					}
+					return dockIPC.Message("unpinned " + data), nil
+				},
+				NeedsData:   true,
+				Usage:       "remove <class>",
+				Description: "unpin an application",
+			},
+			"toggle": {
+				Handler: func(data string) (interface{}, error) {
+					if slices.Contains(*appState.GetPinned(), data) {
+						if err := app.UnpinApp(data, appState); err != nil {
+							return nil, err
+						}
+						return dockIPC.Message("unpinned " + data), nil
+					}
+
+					if err := app.PinApp(data, appState); err != nil {
+						return nil, err
+					}
+					return dockIPC.Message("pinned " + data), nil
+				},
+				NeedsData:   true,
+				Usage:       "toggle <class>",
+				Description: "pin or unpin an application",
+			},
+		},
+	}
+}
+
+func layerCommand(appState *state.State) Command {
+	get := Action{
+		Handler: func(data string) (interface{}, error) {
+			dock := app.FocusedDock(appState)
+			if dock == nil {
+				return nil, errNoDock()
+			}
+
+			layerctl := dock.GetLayerctl()
+			return layerInfo{
+				Mode:     layerctl.GetMode(),
+				Layer:    layerctl.GetLayerName(),
+				Visible:  layerctl.GetVisible(),
+				Special:  layerctl.GetSpecial(),
+				Specials: layerctl.GetSpecialWorkspaces(),
+				Position: appState.GetSettings().Position,
+			}, nil
+		},
+		Usage:       "get",
+		Description: "show the dock layer mode",
+	}
+
+	return Command{
+		Description: "dock layer",
+		Default:     &get,
+		Actions: map[string]Action{
+			"get": get,
+			"set": {
+				Handler: func(data string) (interface{}, error) {
+					validate := func(*layering.Control) error {
+						return layering.ValidateMode(data)
+					}
+					return eachLayerctl(appState, validate, func(layerctl *layering.Control) (string, error) {
+						err := layerctl.SetMode(data)
+						return "layer mode " + layerctl.GetMode(), err
+					})
+				},
+				NeedsData:   true,
+				Usage:       "set <exclusive|smart|normal>",
+				Description: "switch the layer mode",
+			},
+			"toggle": {
+				Handler: func(data string) (interface{}, error) {
+					validate := func(layerctl *layering.Control) error {
+						_, err := layerctl.ToggleTarget(data)
+						return err
+					}
+					return eachLayerctl(appState, validate, func(layerctl *layering.Control) (string, error) {
+						err := layerctl.ToggleMode(data)
+						return "layer mode " + layerctl.GetMode(), err
+					})
+				},
+				Usage:       "toggle [exclusive|smart|normal]",
+				Description: "switch between the configured mode and another one (smart by default)",
+			},
+			"level": {
+				Handler: func(data string) (interface{}, error) {
+					validate := func(layerctl *layering.Control) error {
+						return layerctl.ValidateLayerName(data)
+					}
+					return eachLayerctl(appState, validate, func(layerctl *layering.Control) (string, error) {
+						err := layerctl.SetLayerName(data)
+						return "layer " + layerctl.GetLayerName(), err
+					})
+				},
+				NeedsData:   true,
+				Usage:       "level <background|bottom|top|overlay>",
+				Description: "change the layer used outside of SmartView",
+			},
+		},
+	}
+}
+
+// itemInfos merges the items of all docks by class, a window shown by several docks is listed once
+func itemInfos(appState *state.State) []itemInfo {
+	infos := map[string]*itemInfo{}
+	seen := map[string]bool{}
+
+	for _, dock := range appState.Docks() {
+		for _, item := range dock.GetList().GetMap() {
+			itemInfo := newItemInfo(item)
+
+			info, exist := infos[item.ClassName]
+			if !exist {
+				info = &itemInfo
+				info.Windows = []windowInfo{}
+				infos[item.ClassName] = info
+			}
+
+			for _, window := range itemInfo.Windows {
+				if !seen[window.Address] {
+					seen[window.Address] = true
+					info.Windows = append(info.Windows, window)
+				}
+			}
+		}
+	}
+
+	items := []itemInfo{}
+	for _, info := range infos {
+		sort.Slice(info.Windows, func(i, j int) bool {
+			return info.Windows[i].Address < info.Windows[j].Address
+		})
+		items = append(items, *info)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Class < items[j].Class
+	})
+
+	return items
+}
+
+func sortedWindows(item *item.Item) []*ipc.Client {
+	var windows []*ipc.Client
+	for _, window := range item.Windows {
+		windows = append(windows, window)
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Address < windows[j].Address
+	})
+
+	return windows
+}
+
+func newItemInfo(item *item.Item) itemInfo {
+	info := itemInfo{
+		Class:   item.ClassName,
+		Name:    item.App.GetName(),
+		Pinned:  item.IsPinned(),
+		Windows: []windowInfo{},
+	}
+
+	for _, window := range sortedWindows(item) {
+		info.Windows = append(info.Windows, windowInfo{
+			Address:   window.Address,
+			Class:     item.ClassName,
+			Title:     window.Title,
+			Workspace: window.Workspace.Name,
+			Monitor:   window.Monitor,
+		})
+	}
+
+	return info
+}
+
+// findItems returns the items of the class, there are several with GroupMode workspace or none.
+// The dock on the focused monitor is preferred.
+func findItems(className string, appState *state.State) ([]*item.Item, error) {
+	docks := appState.Docks()
+	if dock := app.FocusedDock(appState); dock != nil {
+		docks = append([]*state.State{dock}, docks...)
+	}
+
+	for _, dock := range docks {
+		var items []*item.Item
+		for _, item := range sortedItems(dock) {
+			if item.ClassName == className {
+				items = append(items, item)
+			}
+		}
+
+		if len(items) > 0 {
+			return items, nil
+		}
+	}
+
+	return nil, dockIPC.NewError(dockIPC.CodeNotFound, "item not found: %s", className)
+}
+
+// sortedItems returns the items of the dock by key, the one keyed by the class first
+func sortedItems(dock *state.State) []*item.Item {
+	var items []*item.Item
+	for _, item := range dock.GetList().GetMap() {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	return items
+}
+
+func searchWindow(address string, appState *state.State) (*item.Item, *ipc.Client, error) {
+	var err error
+	for _, dock := range appState.Docks() {
+		var item *item.Item
+		var window *ipc.Client
+		item, window, err = dock.GetList().SearchWindow(address)
+		if err == nil {
+			return item, window, nil
+		}
+	}
+
+	if err == nil {
+		err = errNoDock()
+	}
+	return nil, nil, err
+}
+
+// eachLayerctl validates the change on every dock before applying it,
+// so that a bad argument leaves all of them as they were
+func eachLayerctl(appState *state.State, validate func(layerctl *layering.Control) error, change func(layerctl *layering.Control) (string, error)) (interface{}, error) {
+	docks := appState.Docks()
+	if len(docks) == 0 {
+		return nil, errNoDock()
+	}
+
+	for _, dock := range docks {
+		if err := validate(dock.GetLayerctl()); err != nil {
+			return nil, dockIPC.NewError(dockIPC.CodeArgs, "%v", err)
+		}
+	}
+
+	// the docks normally end in the same state, list each outcome once
+	var messages []string
+	for _, dock := range docks {
+		message, err := change(dock.GetLayerctl())
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+
+	return dockIPC.Message(strings.Join(messages, "; ")), nil
+}
+
+// launchDesktop starts an app without an item, the class is the exec
+// when there is no desktop entry
+func launchDesktop(className string) (interface{}, error) {
+	desktopApp, _ := desktop.New(className)
+	dockIPC.SendEvent("launch", className)
+	if err := desktopApp.Run(); err != nil {
+		return nil, err
+	}
+	return dockIPC.Message("launched " + className), nil
+}
+
+func errNoDock() error {
+	return dockIPC.NewError(dockIPC.CodeGeneral, "no dock is running")
+}
+
+// activate launches an app without windows, otherwise focuses the window
+// following the active one
+func activate(items []*item.Item) (interface{}, error) {
+	var windows []*ipc.Client
+	for _, item := range items {
+		windows = append(windows, sortedWindows(item)...)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Address < windows[j].Address
+	})
+
+	if len(windows) == 0 {
+		items[0].Launch()
+		return dockIPC.Message("launched " + items[0].ClassName), nil
+	}
+
+	target := windows[0]
+	active, err := ipc.GetActiveWindow()
+	if err == nil {
+		for i, window := range windows {
+			if window.Address == active.Address {
+				target = windows[(i+1)%len(windows)]
+				break
+			}
+		}
+	}
+
+	err = hyprdispatch.Run(hyprdispatch.FocusWindow(target.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	return dockIPC.Message("focused " + target.Address), nil
+}
+
+func windowDispatch(cmd hyprdispatch.Command, address string, appState *state.State) (interface{}, error) {
+	_, _, err := searchWindow(address, appState)
+	if err != nil {
+		return nil, dockIPC.NewError(dockIPC.CodeNotFound, "%v", err)
+	}
+
+	err = hyprdispatch.Run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return dockIPC.Message(cmd.Dispatcher + " " + address), nil
+}
+
+func reloadCommand(appState *state.State) Command {
+	reload := Action{
+		Handler: func(data string) (interface{}, error) {
+			if err := app.Reload(appState); err != nil {
+				return nil, dockIPC.NewError(dockIPC.CodeGeneral, "reload failed: %v", err)
+			}
+			return dockIPC.Message("config reloaded"), nil
+		},
+		Description: "re-read hypr-dock.conf and the current theme",
+	}
+
+	return Command{
+		Description: "reload the config and the theme",
+		Actions:     map[string]Action{},
+		Default:     &reload,
+	}
+}
+
+type configInfo struct {
+	Config string `json:"config"`
+	Theme  string `json:"theme"`
+}
+
+func configCommand(appState *state.State) Command {
+	get := Action{
+		Handler: func(data string) (interface{}, error) {
+			settings := appState.GetSettings()
+			return configInfo{
+				Config: settings.ConfigPath,
+				Theme:  settings.CurrentTheme,
+			}, nil
+		},
+		Usage:       "get",
+		Description: "show the config file and the current theme",
+	}
+
+	return Command{
+		Description: "dock config",
+		Default:     &get,
+		Actions: map[string]Action{
+			"get": get,
+			"load": {
+				Handler: func(data string) (interface{}, error) {
+					settings := appState.GetSettings()
+					prev := settings.ConfigPath
+
+					if err := settings.SetConfigPath(data); err != nil {
+						return nil, dockIPC.NewError(dockIPC.CodeNotFound, "%v", err)
+					}
+
+					if err := app.Reload(appState); err != nil {
+						settings.ConfigPath = prev
+						return nil, dockIPC.NewError(dockIPC.CodeGeneral, "reload failed: %v", err)
+					}
+					return dockIPC.Message("config " + settings.ConfigPath), nil
+				},
+				NeedsData:   true,
+				Usage:       "load <file>",
+				Description: "switch to another config file",
+			},
+			"theme": {
+				Handler: func(data string) (interface{}, error) {
+					settings := appState.GetSettings()
+					prev := settings.ThemeOverride
+
+					if err := settings.SetTheme(data); err != nil {
+						return nil, dockIPC.NewError(dockIPC.CodeNotFound, "%v", err)
+					}
+
+					if err := app.Reload(appState); err != nil {
+						settings.ThemeOverride = prev
+						return nil, dockIPC.NewError(dockIPC.CodeGeneral, "reload failed: %v", err)
+					}
+					return dockIPC.Message("theme " + settings.CurrentTheme), nil
+				},
+				NeedsData:   true,
+				Usage:       "theme <name>",
+				Description: "switch to another theme",
+			},
+		},
+	}
+}
+
+func quitCommand() Command {
+	quit := Action{
+		Handler: func(data string) (interface{}, error) {
+			// let the reply reach the client before the main loop stops
+			glib.TimeoutAdd(100, func() {
+				gtk.MainQuit()
+			})
+			return dockIPC.Message("bye"), nil
+		},
+		Description: "close the dock",
+	}
+
+	return Command{
+		Description: "close the dock",
+		Actions:     map[string]Action{},
+		Default:     &quit,
+	}
+}
+
+func resyncCommand(appState *state.State) Command {
+	resync := Action{
+		Handler: func(data string) (interface{}, error) {
+			if err := ipc.ResyncCache(); err != nil {
+				return nil, dockIPC.NewError(dockIPC.CodeGeneral, "resync failed: %v", err)
+			}
+			var result app.ResyncResult
+			for _, dock := range appState.Docks() {
+				dockResult := app.Resync(ipc.CachedClients(), dock)
+				result.Added += dockResult.Added
+				result.Removed += dockResult.Removed
+				result.Renamed += dockResult.Renamed
+				result.Regrouped += dockResult.Regrouped
+			}
+			return result, nil
+		},
+		Description: "reload the windows from Hyprland and update the dock",
+	}
+
+	return Command{
+		Description: "add missing and remove stale windows",
+		Actions:     map[string]Action{},
+		Default:     &resync,
+	}
+}
+
+type eventStats struct {
+	Received     uint64 `json:"received"`
+	Delivered    uint64 `json:"delivered"`
+	Dropped      uint64 `json:"dropped"`
+	Invalid      uint64 `json:"invalid"`
+	Queued       int    `json:"queued"`
+	SlowHandlers uint64 `json:"slowHandlers"`
+	SlowestMs    int64  `json:"slowestMs"`
+	SlowestEvent string `json:"slowestEvent"`
+}
+
+func eventsCommand() Command {
+	stats := Action{
+		Handler: func(data string) (interface{}, error) {
+			s := ipc.GetEventStats()
+			return eventStats{
+				Received:     s.Received,
+				Delivered:    s.Delivered,
+				Dropped:      s.Dropped,
+				Invalid:      s.Invalid,
+				Queued:       s.Queued,
+				SlowHandlers: s.SlowHandlers,
+				SlowestMs:    s.SlowestHandler.Milliseconds(),
+				SlowestEvent: s.SlowestEvent,
+			}, nil
+		},
+		Usage:       "stats",
+		Description: "show Hyprland event delivery counters",
+	}
+
+	return Command{
+		Description: "Hyprland events",
+		Default:     &stats,
+		Actions: map[string]Action{
+			"stats": stats,
+		},
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"hypr-dock/internal/item"
 	"hypr-dock/internal/pkg/utils"
 	"hypr-dock/internal/settings"
-	"hypr-dock/pkg/ipc"
 
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/gtk"
@@ -26,13 +25,13 @@ type Control struct {
 
 func New(item *item.Item, settings *settings.Settings, log hclog.Logger) *Control {
 	zeroHandler := func() {
-		item.App.Run()
+		item.Launch()
 	}
 
 	singleHandler := func() {
 		client, ok := utils.GetSingleValue(item.Windows)
 		if ok {
-			ipc.Hyprctl("dispatch focuswindow address:" + client.Address)
+			item.FocusWindow(client.Address)
 		}
 	}
 
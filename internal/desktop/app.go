@@ -166,6 +166,21 @@ func (a *App) Run() error {
 	return run(a.exec)
 }
 
+// Open starts the app with the files or URIs
+func (a *App) Open(uris []string) error {
+	commands, err := ExpandExec(a.exec, uris)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		if err := Launch(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (a *Action) Run() error {
 	return run(a.exec)
 }
@@ -1,11 +1,15 @@
 package desktop
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strings"
 )
 
+var ErrNoFiles = errors.New("the application does not open files")
+
 var desktopPlaceholders = map[string]bool{
 	"%f": true,
 	"%F": true,
@@ -26,8 +30,11 @@ func Launch(command string) error {
 	cmd := exec.Command("sh", "-c", command)
 
 	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("unable to launch %s: %v", command, err)
+	}
 
-	return fmt.Errorf("unable to launch %s: %v", command, err)
+	return nil
 }
 
 func CleanExec(execLine string) (string, error) {
@@ -46,6 +53,92 @@ func CleanExec(execLine string) (string, error) {
 	return strings.Join(filteredArgs, " "), nil
 }
 
+// ExpandExec puts the files or URIs in place of the %f, %F, %u and %U field codes.
+// An app taking a single file gets a command per file.
+func ExpandExec(execLine string, uris []string) ([]string, error) {
+	args, err := splitCommandLine(execLine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command line: %w", err)
+	}
+
+	code := ""
+	for _, arg := range args {
+		for _, c := range []string{"%F", "%U", "%f", "%u"} {
+			if code == "" && strings.Contains(arg, c) {
+				code = c
+			}
+		}
+	}
+
+	var values []string
+	switch code {
+	case "":
+		return nil, ErrNoFiles
+	case "%f", "%F":
+		values = localPaths(uris)
+	case "%u", "%U":
+		values = uris
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no files to open with %s", code)
+	}
+
+	if code == "%F" || code == "%U" {
+		return []string{expandArgs(args, code, values)}, nil
+	}
+
+	var commands []string
+	for _, value := range values {
+		commands = append(commands, expandArgs(args, code, []string{value}))
+	}
+	return commands, nil
+}
+
+func expandArgs(args []string, code string, values []string) string {
+	var expanded []string
+	for _, arg := range args {
+		switch {
+		case arg == code:
+			for _, value := range values {
+				expanded = append(expanded, shellQuote(value))
+			}
+		case strings.Contains(arg, code):
+			// e.g. --file=%F, the argument is repeated for every value
+			for _, value := range values {
+				expanded = append(expanded, shellQuote(strings.ReplaceAll(arg, code, value)))
+			}
+		case containsPlaceholder(arg):
+			continue
+		default:
+			expanded = append(expanded, shellQuote(strings.ReplaceAll(arg, "%%", "%")))
+		}
+	}
+
+	return strings.Join(expanded, " ")
+}
+
+// localPaths turns file:// URIs into paths and drops the remote ones
+func localPaths(uris []string) []string {
+	var paths []string
+	for _, uri := range uris {
+		if !strings.Contains(uri, "://") {
+			paths = append(paths, uri)
+			continue
+		}
+
+		u, err := url.Parse(uri)
+		if err == nil && u.Scheme == "file" {
+			paths = append(paths, u.Path)
+		}
+	}
+	return paths
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func containsPlaceholder(s string) bool {
 	for i := 0; i < len(s)-1; i++ {
 		if s[i] == '%' {
@@ -0,0 +1,101 @@
+package desktop
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		args []string
+	}{
+		{"firefox %u", []string{"firefox", "%u"}},
+		{`  code   --new-window  %F `, []string{"code", "--new-window", "%F"}},
+		{`sh -c "echo a b"`, []string{"sh", "-c", "echo a b"}},
+		{`app --title="My App" %f`, []string{"app", "--title=My App", "%f"}},
+		{`app \"quoted\\ %U`, []string{"app", `"quoted\`, "%U"}},
+	}
+
+	for _, test := range tests {
+		args, err := splitCommandLine(test.line)
+		if err != nil {
+			t.Fatalf("splitCommandLine(%q): %v", test.line, err)
+		}
+		if !slices.Equal(args, test.args) {
+			t.Errorf("splitCommandLine(%q) = %q, want %q", test.line, args, test.args)
+		}
+	}
+
+	if _, err := splitCommandLine(`app "unclosed`); err == nil {
+		t.Error("unclosed quotes accepted")
+	}
+}
+
+func TestExpandExec(t *testing.T) {
+	files := []string{"file:///home/user/a.txt", "/home/user/it's b.txt"}
+	uris := []string{"https://example.org/", "file:///home/user/a.txt"}
+
+	tests := []struct {
+		name     string
+		exec     string
+		uris     []string
+		commands []string
+	}{
+		{
+			"file per command", "gedit %f", files,
+			[]string{"'gedit' '/home/user/a.txt'", `'gedit' '/home/user/it'\''s b.txt'`},
+		},
+		{
+			"all files", "code --new-window %F", files,
+			[]string{`'code' '--new-window' '/home/user/a.txt' '/home/user/it'\''s b.txt'`},
+		},
+		{
+			"remote files are dropped", "mpv %F", uris,
+			[]string{"'mpv' '/home/user/a.txt'"},
+		},
+		{
+			"url per command", "firefox %u", uris,
+			[]string{"'firefox' 'https://example.org/'", "'firefox' 'file:///home/user/a.txt'"},
+		},
+		{
+			"all urls", "chromium %U", uris,
+			[]string{"'chromium' 'https://example.org/' 'file:///home/user/a.txt'"},
+		},
+		{
+			"field code in an argument", "app --files=%F", files,
+			[]string{`'app' '--files=/home/user/a.txt' '--files=/home/user/it'\''s b.txt'`},
+		},
+		{
+			"other field codes are removed", `app %i --name="%c" %%x %U`, uris[:1],
+			[]string{"'app' '%x' 'https://example.org/'"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			commands, err := ExpandExec(test.exec, test.uris)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(commands, test.commands) {
+				t.Fatalf("got %q, want %q", commands, test.commands)
+			}
+		})
+	}
+}
+
+func TestExpandExecErrors(t *testing.T) {
+	if _, err := ExpandExec("kitty", []string{"/tmp/a"}); !errors.Is(err, ErrNoFiles) {
+		t.Errorf("no field code: got %v, want ErrNoFiles", err)
+	}
+
+	if _, err := ExpandExec("gedit %F", []string{"https://example.org/"}); err == nil {
+		t.Error("remote file accepted by a file field code")
+	}
+
+	if _, err := ExpandExec(`gedit "%F`, []string{"/tmp/a"}); err == nil {
+		t.Error("unclosed quotes accepted")
+	}
+}
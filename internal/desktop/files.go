@@ -1,9 +1,11 @@
 package desktop
 
 import (
+	"fmt"
 	"hypr-dock/pkg/ini"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -55,3 +57,23 @@ func newTable() map[string]string {
 
 	return res
 }
+
+// ClassName returns the class of the app described by the desktop file,
+// StartupWMClass or the file name like SearchDesktopFile expects
+func ClassName(path string) (string, error) {
+	data, err := ini.GetMap(path, "Desktop Entry")
+	if err != nil {
+		return "", err
+	}
+
+	general, exist := data["Desktop Entry"]
+	if !exist {
+		return "", fmt.Errorf("section \"%v\" not found in %s", "Desktop Entry", path)
+	}
+
+	if className := general["StartupWMClass"]; className != "" {
+		return className, nil
+	}
+
+	return strings.TrimSuffix(filepath.Base(path), ".desktop"), nil
+}
@@ -1,7 +1,7 @@
 package hyprEvents
 
 import (
-	"strings"
+	"time"
 
 	"github.com/gotk3/gotk3/glib"
 
@@ -10,76 +10,200 @@ import (
 	"hypr-dock/pkg/ipc"
 )
 
+// recheck of the class after openwindow, later than the cache resync
+const regroupDelay = 2 * time.Second
+
 func Init(appState *state.State) {
-	ipc.AddEventListener("windowtitlev2", func(event string) {
-		windowTitleHandler(event, appState)
+	ipc.Subscribe(func(e ipc.WindowTitleV2) {
+		windowTitleHandler(e, appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.OpenWindow) {
+		openwindowHandler(e, appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.CloseWindow) {
+		closewindowHandler(e, appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.ActiveWindowV2) {
+		activewindowHandler(e, appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.Urgent) {
+		forEachDock(appState, func(dock *state.State) {
+			app.SetUrgent(e.Address, dock)
+		})
+	}, true)
+
+	ipc.Subscribe(func(e ipc.MoveWindowV2) {
+		updateWindows(appState, e.Address)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.ChangeFloatingMode) {
+		updateWindows(appState, e.Address)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.Fullscreen) {
+		fullscreenHandler(appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.MoveWorkspaceV2) {
+		moveworkspaceHandler(e, appState)
+	}, true)
+
+	ipc.Subscribe(func(e ipc.ActiveSpecial) {
+		activatespecialHandler(e, appState)
 	}, true)
 
-	ipc.AddEventListener("openwindow", func(event string) {
-		openwindowHandler(event, appState)
+	ipc.Subscribe(func(e ipc.FocusedMon) {
+		glib.IdleAdd(func() {
+			app.FollowMonitor(e.Monitor, appState)
+		})
 	}, true)
 
-	ipc.AddEventListener("closewindow", func(event string) {
-		closewindowHandler(event, appState)
+	ipc.Subscribe(func(e ipc.MonitorAddedV2) {
+		syncDocks(appState)
 	}, true)
 
-	ipc.AddEventListener("activespecial", func(event string) {
-		activatespecialHandler(event, appState)
+	ipc.Subscribe(func(e ipc.MonitorRemoved) {
+		syncDocks(appState)
 	}, true)
 
+	// windows opened or closed while disconnected never sent events
+	ipc.OnReconnect(func() {
+		forEachDock(appState, func(dock *state.State) {
+			app.Resync(ipc.CachedClients(), dock)
+		})
+		syncDocks(appState)
+	})
+
 	go ipc.InitHyprEvents()
 }
 
-func windowTitleHandler(event string, appState *state.State) {
-	data := eventHandler(event, 2)
-	address := "0x" + strings.TrimSpace(data[0])
-	go app.ChangeWindowTitle(address, data[1], appState)
+func windowTitleHandler(e ipc.WindowTitleV2, appState *state.State) {
+	forEachDock(appState, func(dock *state.State) {
+		app.ChangeWindowTitle(e.Address, e.Title, dock)
+	})
+
+	// a window grouped by its title may get the class later,
+	// the cache resyncs classless windows on a title change
+	client, exist := ipc.CachedClient(e.Address)
+	if !exist || client.Class != "" {
+		return
+	}
+	regroupLater(e.Address, appState)
 }
 
-func activatespecialHandler(event string, appState *state.State) {
-	log := appState.GetLogger()
+func activewindowHandler(e ipc.ActiveWindowV2, appState *state.State) {
+	forEachDock(appState, func(dock *state.State) {
+		app.SetActiveWindow(e.Address, dock)
+	})
 
-	data := eventHandler(event, 2)
-	log.Debug("Received activespecial event:", "event", data)
+	// the cache took the class from the preceding activewindow event
+	if client, exist := ipc.CachedClient(e.Address); exist {
+		regroupWindow(client, appState)
+	}
+}
+
+func regroupWindow(client ipc.Client, appState *state.State) {
+	forEachDock(appState, func(dock *state.State) {
+		app.RegroupWindow(client, dock)
+	})
+}
+
+// forEachDock runs the handler for every dock on the gtk main loop
+func forEachDock(appState *state.State, handler func(dock *state.State)) {
+	glib.IdleAdd(func() {
+		for _, dock := range appState.Docks() {
+			handler(dock)
+		}
+	})
+}
+
+// the cache has already reloaded the monitors, its listeners run first
+func syncDocks(appState *state.State) {
+	glib.IdleAdd(func() {
+		app.SyncDocks(appState)
+	})
+}
+
+func activatespecialHandler(e ipc.ActiveSpecial, appState *state.State) {
+	log := appState.GetLogger()
+	log.Debug("Received activespecial event:", "workspace", e.Workspace, "monitor", e.Monitor)
 
-	if data[0] == "special:special" {
-		log.Debug("Special workspace activated")
-		appState.GetLayerctl().SetSpecial(true)
+	if _, ok := ipc.SpecialWorkspaceName(e.Workspace); ok {
+		log.Debug("Special workspace activated", "workspace", e.Workspace)
 	} else {
-		log.Debug("Special workspace deactivated")
-		appState.GetLayerctl().SetSpecial(false)
+		log.Debug("Special workspace deactivated", "monitor", e.Monitor)
 	}
+
+	forEachDock(appState, func(dock *state.State) {
+		dock.GetLayerctl().SetSpecial(e.Monitor, e.Workspace)
+	})
 }
 
-func openwindowHandler(event string, appState *state.State) {
-	data := eventHandler(event, 4)
-	address := "0x" + strings.TrimSpace(data[0])
-	windowClient, err := ipc.SearchClientByAddress(address)
+func openwindowHandler(e ipc.OpenWindow, appState *state.State) {
+	windowClient, err := ipc.SearchClientByAddress(e.Address)
 	if err != nil {
-		appState.GetLogger().Error("Client not found", "address", address, "error", err)
+		appState.GetLogger().Error("Client not found", "address", e.Address, "error", err)
 	} else {
-		glib.IdleAdd(func() {
-			app.InitNewItemInIPC(windowClient, appState)
+		forEachDock(appState, func(dock *state.State) {
+			app.InitNewItemInIPC(windowClient, dock)
 		})
 	}
+
+	// the cache is resynced shortly after openwindow, recheck the class then
+	regroupLater(e.Address, appState)
 }
 
-func closewindowHandler(event string, appState *state.State) {
-	data := eventHandler(event, 1)
-	address := "0x" + strings.TrimSpace(data[0])
-	glib.IdleAdd(func() {
-		app.RemoveApp(address, appState)
+// regroupLater moves the window to the item of its class once the cache is resynced
+func regroupLater(address string, appState *state.State) {
+	time.AfterFunc(regroupDelay, func() {
+		if client, exist := ipc.CachedClient(address); exist {
+			regroupWindow(client, appState)
+		}
 	})
 }
 
-func eventHandler(event string, n int) []string {
-	parts := strings.SplitN(event, ">>", 2)
-	dataClast := strings.TrimSpace(parts[1])
-	dataParts := strings.SplitN(dataClast, ",", n)
+// updateWindows copies the clients from the cache, that has already applied the event
+func updateWindows(appState *state.State, addresses ...string) {
+	var clients []ipc.Client
+	for _, address := range addresses {
+		if client, exist := ipc.CachedClient(address); exist {
+			clients = append(clients, client)
+		}
+	}
 
-	for i := range dataParts {
-		dataParts[i] = strings.TrimSpace(dataParts[i])
+	forEachDock(appState, func(dock *state.State) {
+		for _, client := range clients {
+			app.UpdateWindow(client, dock)
+		}
+	})
+}
+
+// fullscreen has no address, it is always about the active window
+func fullscreenHandler(appState *state.State) {
+	client, exist := ipc.CachedActiveWindow()
+	if !exist {
+		return
+	}
+	updateWindows(appState, client.Address)
+}
+
+func moveworkspaceHandler(e ipc.MoveWorkspaceV2, appState *state.State) {
+	var addresses []string
+	for _, client := range ipc.CachedClients() {
+		if client.Workspace.Id == e.ID {
+			addresses = append(addresses, client.Address)
+		}
 	}
 
-	return dataParts
+	updateWindows(appState, addresses...)
+}
+
+func closewindowHandler(e ipc.CloseWindow, appState *state.State) {
+	forEachDock(appState, func(dock *state.State) {
+		app.RemoveApp(e.Address, dock)
+	})
 }
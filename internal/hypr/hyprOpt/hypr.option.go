@@ -51,13 +51,13 @@ func GetGap() ([]int, error) {
 	return outValues, nil
 }
 
-func GapChangeEvent(handler func(gap []int)) {
+func GapChangeEvent(handler func(gap []int)) *ipc.EventListener {
 	preGaps, err := GetGap()
 	if err != nil {
-		return
+		return nil
 	}
 
-	ipc.AddEventListener("configreloaded", func(e string) {
+	return ipc.Subscribe(func(e ipc.ConfigReloaded) {
 		gaps, err := GetGap()
 		if err != nil {
 			return
@@ -0,0 +1,167 @@
+package hyprOpt
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/ipctest"
+)
+
+var srv *ipctest.Server
+
+func TestMain(m *testing.M) {
+	var err error
+	srv, err = ipctest.New()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer srv.Close()
+
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		go ipc.InitHyprEvents()
+		if err := srv.WaitSubscribers(1, time.Second); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		return m.Run()
+	}()
+
+	os.Exit(code)
+}
+
+func setGaps(t *testing.T, gaps gapsOut) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	gaps.Option = "general:gaps_out"
+	if err := srv.SetOption(gaps.Option, gaps); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetGap(t *testing.T) {
+	gaps, err := GetGap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gaps, []int{20, 20, 20, 20}) {
+		t.Fatalf("got %v from the fixtures", gaps)
+	}
+
+	tests := []struct {
+		name string
+		gaps gapsOut
+		want []int
+	}{
+		{"rounded", gapsOut{Custom: " 5.6 -3 0 10 ", Set: true}, []int{6, 0, 0, 10}},
+		{"unset", gapsOut{Custom: "20 20 20 20"}, nil},
+		{"empty", gapsOut{Set: true}, nil},
+		{"not a number", gapsOut{Custom: "20 auto", Set: true}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setGaps(t, test.gaps)
+
+			gaps, err := GetGap()
+			if test.want == nil {
+				if err == nil {
+					t.Fatalf("got %v, want an error", gaps)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(gaps, test.want) {
+				t.Fatalf("got %v, want %v", gaps, test.want)
+			}
+		})
+	}
+}
+
+func TestGetGapMissingOption(t *testing.T) {
+	t.Cleanup(func() {
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	srv.SetReply("j/getoption general:gaps_out", []byte("no such option"))
+
+	if gaps, err := GetGap(); err == nil {
+		t.Fatalf("got %v, want an error", gaps)
+	}
+}
+
+func TestGapChangeEvent(t *testing.T) {
+	changes := make(chan []int, 4)
+	listener := GapChangeEvent(func(gaps []int) {
+		changes <- gaps
+	})
+	if listener == nil {
+		t.Fatal("no listener with readable gaps")
+	}
+	defer listener.Remove()
+
+	reload := func() {
+		t.Helper()
+
+		// the listeners run in order, the marker comes after GapChangeEvent
+		handled := make(chan struct{}, 1)
+		marker := ipc.Subscribe(func(e ipc.ConfigReloaded) {
+			handled <- struct{}{}
+		}, true)
+		defer marker.Remove()
+
+		if err := srv.SendEvent("configreloaded"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-handled:
+		case <-time.After(2 * time.Second):
+			t.Fatal("configreloaded was not handled")
+		}
+	}
+
+	reload()
+	if len(changes) != 0 {
+		t.Fatalf("handler called without a change: %v", <-changes)
+	}
+
+	setGaps(t, gapsOut{Custom: "30 30 30 30", Set: true})
+	reload()
+	select {
+	case gaps := <-changes:
+		if !slices.Equal(gaps, []int{30, 30, 30, 30}) {
+			t.Fatalf("got %v", gaps)
+		}
+	default:
+		t.Fatal("handler not called after the gaps changed")
+	}
+}
+
+func TestGapChangeEventUnreadable(t *testing.T) {
+	setGaps(t, gapsOut{})
+
+	if listener := GapChangeEvent(func([]int) {}); listener != nil {
+		listener.Remove()
+		t.Fatal("got a listener without gaps")
+	}
+}
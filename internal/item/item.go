@@ -1,8 +1,8 @@
 package item
 
 import (
-	"log"
 	"slices"
+	"strconv"
 
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/gtk"
@@ -16,13 +16,16 @@ import (
 	"hypr-dock/internal/pkg/utils"
 	"hypr-dock/internal/settings"
 
+	"hypr-dock/pkg/dockIPC"
 	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/dispatch"
 )
 
 type Item struct {
 	Windows        map[string]*ipc.Client
 	App            *desktop.App
 	ClassName      string
+	Key            string // key in the List, the class unless GroupMode is workspace or none
 	Button         *gtk.Button
 	ButtonBox      *gtk.Box
 	IndicatorImage *gtk.Image
@@ -30,8 +33,13 @@ type Item struct {
 	Settings   *settings.Settings
 	List       map[string]*Item
 	PinnedList *[]string
+	// Monitor of the dock with the item, "" if the compositor chose it
+	Monitor string
 
-	log hclog.Logger
+	focused     string
+	urgent      map[string]bool
+	onPinChange func(pinned bool)
+	log         hclog.Logger
 }
 
 func New(className string, settings *settings.Settings, log hclog.Logger) (*Item, error) {
@@ -85,52 +93,187 @@ func New(className string, settings *settings.Settings, log hclog.Logger) (*Item
 		ButtonBox:      item,
 		App:            app,
 		ClassName:      className,
+		Key:            className,
 
 		Settings:   settings,
 		List:       nil,
 		PinnedList: nil,
 
-		log: log,
+		urgent: map[string]bool{},
+		log:    log,
 	}, nil
 }
 
 func (i *Item) RemoveWindow(windowAddress string) {
-	if i.IndicatorImage != nil {
-		i.IndicatorImage.Destroy()
-	}
-
 	delete(i.Windows, windowAddress)
-	instances := len(i.Windows)
 
-	newImage, err := indicator.New(instances, i.Settings)
-	if err == nil {
-		appendInducator(i.ButtonBox, newImage, i.Settings.Position)
+	if i.focused == windowAddress {
+		i.SetFocused("")
 	}
-	i.IndicatorImage = newImage
 
-	if instances == 0 && i.Settings.Preview.Mode != "none" {
-		i.Button.SetTooltipText(i.App.GetName())
+	if i.urgent[windowAddress] {
+		i.SetUrgent(windowAddress, false)
+	} else {
+		i.updateIndicator()
 	}
+	i.UpdateSpecial()
+	i.UpdateTooltip()
 }
 
 func (i *Item) AddWindow(ipcClient ipc.Client) {
+	i.Windows[ipcClient.Address] = &ipcClient
+
+	i.updateIndicator()
+	i.UpdateSpecial()
+	i.UpdateTooltip()
+}
+
+// UpdateTooltip shows the app name, the window title of an item
+// with GroupMode = none, or nothing when the preview shows the windows
+func (i *Item) UpdateTooltip() {
+	if len(i.Windows) != 0 && i.Settings.Preview.Mode != "none" {
+		i.Button.SetTooltipText("")
+		return
+	}
+
+	if window, ok := utils.GetSingleValue(i.Windows); ok && i.Settings.GroupMode == "none" {
+		i.Button.SetTooltipText(window.Title)
+		return
+	}
+
+	i.Button.SetTooltipText(i.App.GetName())
+}
+
+// SetFocused adds the "focused" class to the button if the active window
+// belongs to the item, and removes it otherwise
+func (i *Item) SetFocused(address string) {
+	if _, exist := i.Windows[address]; !exist {
+		address = ""
+	}
+	i.focused = address
+
+	// the window got the attention it asked for
+	if i.urgent[address] {
+		i.SetUrgent(address, false)
+	}
+
+	i.setButtonClass("focused", address != "")
+}
+
+// SetUrgent marks the window as demanding attention. While any window is urgent,
+// the button has the "urgent" class and the UrgentAnimation class,
+// and the indicator is point/urgent.* if the theme has it.
+func (i *Item) SetUrgent(address string, urgent bool) {
+	if _, exist := i.Windows[address]; !exist && urgent {
+		return
+	}
+
+	if urgent {
+		i.urgent[address] = true
+	} else {
+		delete(i.urgent, address)
+	}
+
+	isUrgent := i.IsUrgent()
+	i.setButtonClass("urgent", isUrgent)
+
+	for _, animation := range []string{"bounce", "pulse"} {
+		i.setButtonClass(animation, isUrgent && i.Settings.UrgentAnimation == animation)
+	}
+
+	i.updateIndicator()
+}
+
+// UpdateSpecial adds the "special" class to the button when SpecialWindows = mark
+// and every window of the item is in a special workspace
+func (i *Item) UpdateSpecial() {
+	special := i.Settings.SpecialWindows == "mark" && len(i.Windows) > 0
+	for _, window := range i.Windows {
+		if !window.InSpecialWorkspace() {
+			special = false
+			break
+		}
+	}
+
+	i.setButtonClass("special", special)
+}
+
+// SpecialWorkspaces returns the names of the special workspaces with windows of the item
+func (i *Item) SpecialWorkspaces() []string {
+	var names []string
+	for _, window := range i.Windows {
+		name, ok := ipc.SpecialWorkspaceName(window.Workspace.Name)
+		if ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// ToggleSpecialWorkspace shows or hides the special workspace, e.g. "term" for special:term
+func (i *Item) ToggleSpecialWorkspace(name string) {
+	i.dispatch(dispatch.ToggleSpecialWorkspace(name))
+}
+
+func (i *Item) IsUrgent() bool {
+	return len(i.urgent) > 0
+}
+
+func (i *Item) UrgentWindows() []string {
+	var addresses []string
+	for address := range i.urgent {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+func (i *Item) setButtonClass(class string, enabled bool) {
+	if i.Button == nil {
+		return
+	}
+
+	context, err := i.Button.GetStyleContext()
+	if err != nil {
+		i.log.Error("Unable to get button style context", "className", i.ClassName, "error", err)
+		return
+	}
+
+	if enabled {
+		context.AddClass(class)
+	} else {
+		context.RemoveClass(class)
+	}
+}
+
+// updateIndicator replaces the indicator with the one for the window count or the urgent state
+func (i *Item) updateIndicator() {
 	if i.IndicatorImage != nil {
 		i.IndicatorImage.Destroy()
 	}
 
-	i.Windows[ipcClient.Address] = &ipcClient
-	instances := len(i.Windows)
+	var indicatorImage *gtk.Image
+	var err error
+
+	if i.IsUrgent() {
+		indicatorImage, err = indicator.NewUrgent(i.Settings)
+	}
+	if indicatorImage == nil || err != nil {
+		indicatorImage, err = indicator.New(len(i.Windows), i.Settings)
+	}
 
-	indicatorImage, err := indicator.New(instances, i.Settings)
 	if err == nil {
 		appendInducator(i.ButtonBox, indicatorImage, i.Settings.Position)
+		indicatorImage.Show()
 	}
 
 	i.IndicatorImage = indicatorImage
+}
 
-	if instances != 0 && i.Settings.Preview.Mode != "none" {
-		i.Button.SetTooltipText("")
-	}
+// Focused returns the address of the item window that is active, or ""
+func (i *Item) Focused() string {
+	return i.focused
 }
 
 func (i *Item) IsPinned() bool {
@@ -158,6 +301,13 @@ func (i *Item) TogglePin() {
 	if !pin {
 		*list = append(*list, className)
 		i.log.Trace("Add", className)
+		dockIPC.SendEvent("pin", className)
+	} else {
+		dockIPC.SendEvent("unpin", className)
+	}
+
+	if i.onPinChange != nil {
+		i.onPinChange(!pin)
 	}
 
 	file := i.Settings.PinnedPath
@@ -170,9 +320,135 @@ func (i *Item) TogglePin() {
 	i.log.Trace("File saved successfully!", file, className)
 }
 
+// OnPinChange sets the handler called after the item was pinned or unpinned
+func (i *Item) OnPinChange(handler func(pinned bool)) {
+	i.onPinChange = handler
+}
+
 func (i *Item) Remove() {
 	i.ButtonBox.Destroy()
-	delete(i.List, i.ClassName)
+	delete(i.List, i.Key)
+
+	dockIPC.SendEvent("removeitem", i.ClassName)
+}
+
+func (i *Item) Launch() {
+	dockIPC.SendEvent("launch", i.ClassName)
+
+	err := i.App.Run()
+	if err != nil {
+		i.log.Error("Unable to launch app", "className", i.ClassName, "error", err)
+	}
+}
+
+// Open starts the app with the dropped files or URIs
+func (i *Item) Open(uris []string) {
+	dockIPC.SendEvent("launch", i.ClassName)
+
+	err := i.App.Open(uris)
+	if err != nil {
+		i.log.Error("Unable to open files", "className", i.ClassName, "files", uris, "error", err)
+	}
+}
+
+// CurrentWindows returns the windows of the item as the cache has them,
+// the stored copies are not updated on every focus change
+func (i *Item) CurrentWindows() []ipc.Client {
+	var clients []ipc.Client
+	for address, window := range i.Windows {
+		client, exist := ipc.CachedClient(address)
+		if !exist {
+			client = *window
+		}
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// RecentWindow returns the window of the item that was focused last
+func (i *Item) RecentWindow() (ipc.Client, bool) {
+	return MostRecent(i)
+}
+
+// MostRecent returns the window with the lowest focusHistoryID among the windows of the items
+func MostRecent(items ...*Item) (ipc.Client, bool) {
+	var recent ipc.Client
+	found := false
+	for _, item := range items {
+		for _, client := range item.CurrentWindows() {
+			if !found || client.FocusHistoryID < recent.FocusHistoryID {
+				recent = client
+				found = true
+			}
+		}
+	}
+	return recent, found
+}
+
+func (i *Item) FocusWindow(address string) {
+	i.dispatch(dispatch.FocusWindow(address))
+}
+
+// CloseWindows closes every window of the item in one batch
+func (i *Item) CloseWindows() {
+	var cmds []dispatch.Command
+	for _, address := range i.windowAddresses() {
+		cmds = append(cmds, dispatch.CloseWindow(address))
+	}
+
+	i.batch(cmds...)
+}
+
+// MoveWindowsHere moves every window of the item to the active workspace in one batch
+func (i *Item) MoveWindowsHere() {
+	workspace := ""
+	for _, monitor := range ipc.CachedMonitors() {
+		if monitor.Focused {
+			workspace = strconv.Itoa(monitor.ActiveWorkspace.Id)
+		}
+	}
+
+	if workspace == "" {
+		i.log.Error("Unable to find the active workspace", "className", i.ClassName)
+		return
+	}
+
+	var cmds []dispatch.Command
+	for _, address := range i.windowAddresses() {
+		cmds = append(cmds, dispatch.MoveToWorkspaceSilent(workspace, address))
+	}
+
+	i.batch(cmds...)
+}
+
+func (i *Item) windowAddresses() []string {
+	var addresses []string
+	for address := range i.Windows {
+		addresses = append(addresses, address)
+	}
+
+	slices.Sort(addresses)
+	return addresses
+}
+
+func (i *Item) dispatch(cmd dispatch.Command) {
+	if err := dispatch.Run(cmd); err != nil {
+		i.log.Error("Dispatch failed", "command", cmd.String(), "error", err)
+	}
+}
+
+func (i *Item) batch(cmds ...dispatch.Command) {
+	results, err := dispatch.Batch(cmds...)
+	if err != nil {
+		i.log.Error("Batch dispatch failed", "className", i.ClassName, "error", err)
+		return
+	}
+
+	for n, err := range results {
+		if err != nil {
+			i.log.Error("Dispatch failed", "command", cmds[n].String(), "error", err)
+		}
+	}
 }
 
 type Position struct {
@@ -201,7 +477,7 @@ func (i *Item) GetCord() (*Position, error) {
 	}
 
 	// get main layer info
-	dock, err := layerinfo.GetDock()
+	dock, err := layerinfo.GetDockOn(i.Monitor)
 	if err != nil {
 		return nil, err
 	}
@@ -210,10 +486,9 @@ func (i *Item) GetCord() (*Position, error) {
 	hyprMonitor, err := ipc.SearchMonitorByName(dock.Monitor)
 	if err != nil {
 		i.log.Error("Error getting monitor:", "error", err)
+		return nil, err
 	}
 
-	log.Println(dock.Y, hyprMonitor.Height)
-
 	// get coord with centring
 	switch pos {
 	case "bottom":
@@ -6,11 +6,10 @@ import (
 
 	"github.com/gotk3/gotk3/gtk"
 	"github.com/gotk3/gotk3/pango"
-	"github.com/hashicorp/go-hclog"
 
-	"hypr-dock/internal/desktop"
 	"hypr-dock/internal/pkg/utils"
-	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/dockIPC"
+	"hypr-dock/pkg/ipc/dispatch"
 )
 
 func (i *Item) WindowsMenu() (*gtk.Menu, error) {
@@ -19,7 +18,7 @@ func (i *Item) WindowsMenu() (*gtk.Menu, error) {
 		return nil, err
 	}
 
-	AddWindowsItemToMenu(menu, i.Windows, i.App, i.log)
+	AddWindowsItemToMenu(menu, i)
 
 	menu.SetName("windows-menu")
 	menu.ShowAll()
@@ -36,7 +35,7 @@ func (i *Item) ContextMenu() (*gtk.Menu, error) {
 	app := i.App
 	actions := app.GetActions()
 
-	AddWindowsItemToMenu(menu, i.Windows, app, i.log)
+	AddWindowsItemToMenu(menu, i)
 
 	if len(i.Windows) != 0 {
 		separator, err := gtk.SeparatorMenuItemNew()
@@ -50,6 +49,7 @@ func (i *Item) ContextMenu() (*gtk.Menu, error) {
 	if actions != nil {
 		for _, action := range actions {
 			exec := func() {
+				dockIPC.SendEvent("launch", i.ClassName)
 				action.Run()
 			}
 
@@ -91,11 +91,42 @@ func (i *Item) ContextMenu() (*gtk.Menu, error) {
 		i.log.Error("Unable to create pin menu item", "error", err)
 	}
 
+	for _, name := range i.SpecialWorkspaces() {
+		scratchpadMenuItem, err := BuildContextItem("Toggle Scratchpad "+name, func() {
+			i.ToggleSpecialWorkspace(name)
+		})
+		if err == nil {
+			menu.Append(scratchpadMenuItem)
+		} else {
+			i.log.Error("Unable to create scratchpad menu item", "error", err)
+		}
+	}
+
+	if len(i.Windows) > 1 {
+		moveMenuItem, err := BuildContextItem("Move All Here", func() {
+			i.MoveWindowsHere()
+		})
+		if err == nil {
+			menu.Append(moveMenuItem)
+		} else {
+			i.log.Error("Unable to create move menu item", "error", err)
+		}
+
+		closeAllMenuItem, err := BuildContextItem("Close All", func() {
+			i.CloseWindows()
+		}, "close-symbolic")
+		if err == nil {
+			menu.Append(closeAllMenuItem)
+		} else {
+			i.log.Error("Unable to create close menu item", "error", err)
+		}
+	}
+
 	if len(i.Windows) == 1 {
 		client, ok := utils.GetSingleValue(i.Windows)
 		if ok {
 			closeMenuItem, err := BuildContextItem("Close", func() {
-				ipc.Hyprctl("dispatch closewindow address:" + client.Address)
+				i.dispatch(dispatch.CloseWindow(client.Address))
 			}, "close-symbolic")
 			if err == nil {
 				menu.Append(closeMenuItem)
@@ -111,10 +142,19 @@ func (i *Item) ContextMenu() (*gtk.Menu, error) {
 	return menu, nil
 }
 
-func AddWindowsItemToMenu(menu *gtk.Menu, windows map[string]*ipc.Client, app *desktop.App, log hclog.Logger) {
-	for _, window := range windows {
+// AddWindowsItemToMenu appends a row per window. The row of the focused window
+// has the "focused" class, with SpecialWindows = mark rows of scratchpad windows have "special".
+func AddWindowsItemToMenu(menu *gtk.Menu, item *Item) {
+	app := item.App
+	log := item.log
+
+	for _, window := range item.Windows {
 		menuItem, err := BuildContextItem(window.Title, func() {
-			go ipc.Hyprctl("dispatch focuswindow address:" + window.Address)
+			go func() {
+				if err := dispatch.Run(dispatch.FocusWindow(window.Address)); err != nil {
+					log.Error("Unable to focus window", "address", window.Address, "error", err)
+				}
+			}()
 		}, app.GetIcon())
 
 		if err != nil {
@@ -122,6 +162,16 @@ func AddWindowsItemToMenu(menu *gtk.Menu, windows map[string]*ipc.Client, app *d
 			continue
 		}
 
+		context, err := menuItem.GetStyleContext()
+		if err == nil {
+			if window.Address == item.focused {
+				context.AddClass("focused")
+			}
+			if item.Settings.SpecialWindows == "mark" && window.InSpecialWorkspace() {
+				context.AddClass("special")
+			}
+		}
+
 		menu.Append(menuItem)
 	}
 }
@@ -141,7 +191,7 @@ func BuildLaunchMenuItem(item *Item) (*gtk.MenuItem, error) {
 	}
 
 	launchMenuItem, err := BuildContextItem(labelText, func() {
-		app.Run()
+		item.Launch()
 	}, app.GetIcon())
 
 	if err != nil {
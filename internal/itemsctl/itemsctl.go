@@ -4,10 +4,15 @@ import (
 	"errors"
 	"hypr-dock/internal/item"
 	"hypr-dock/pkg/ipc"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type List struct {
 	list map[string]*item.Item
+	// keys in the order the items were added
+	order []string
 }
 
 func New() *List {
@@ -24,18 +29,92 @@ func (l *List) Get(className string) *item.Item {
 	return l.list[className]
 }
 
-func (l *List) Add(className string, item *item.Item) {
-	l.list[className] = item
+func (l *List) Add(key string, item *item.Item) {
+	l.list[key] = item
+	l.order = slices.DeleteFunc(l.order, func(name string) bool {
+		return name == key
+	})
+	l.order = append(l.order, key)
 }
 
-func (l *List) Remove(className string) {
-	delete(l.list, className)
+func (l *List) Remove(key string) {
+	delete(l.list, key)
 }
 
 func (l *List) Len() int {
 	return len(l.list)
 }
 
+// Sorted returns the pinned items in the pinned order, followed by the running ones
+// in the order of the policy: "launch", "name", "workspace" or "focus"
+func (l *List) Sorted(policy string, pinned []string) []*item.Item {
+	// Item.Remove deletes from the map directly
+	l.order = slices.DeleteFunc(l.order, func(key string) bool {
+		return l.list[key] == nil
+	})
+
+	var items []*item.Item
+	for _, className := range pinned {
+		if item := l.list[className]; item != nil {
+			items = append(items, item)
+		}
+	}
+
+	// the windows of a pinned app have their own items in some GroupModes
+	var running []*item.Item
+	for _, key := range l.order {
+		if !slices.Contains(pinned, key) {
+			running = append(running, l.list[key])
+		}
+	}
+
+	switch policy {
+	case "name":
+		sort.SliceStable(running, func(i, j int) bool {
+			return strings.ToLower(running[i].App.GetName()) < strings.ToLower(running[j].App.GetName())
+		})
+	case "workspace":
+		sort.SliceStable(running, func(i, j int) bool {
+			return firstWorkspace(running[i]) < firstWorkspace(running[j])
+		})
+	case "focus":
+		sort.SliceStable(running, func(i, j int) bool {
+			return lastFocus(running[i]) < lastFocus(running[j])
+		})
+	}
+
+	return append(items, running...)
+}
+
+// firstWorkspace returns the lowest workspace of the item windows,
+// special workspaces (negative ids) come after the regular ones
+func firstWorkspace(item *item.Item) int {
+	first := -1
+	for _, window := range item.CurrentWindows() {
+		id := window.Workspace.Id
+		if id < 0 {
+			id = 1<<16 - id
+		}
+		if first < 0 || id < first {
+			first = id
+		}
+	}
+
+	if first < 0 {
+		return 1 << 30
+	}
+	return first
+}
+
+// lastFocus returns the lowest focusHistoryID of the item windows, 0 is the focused one
+func lastFocus(item *item.Item) int {
+	window, ok := item.RecentWindow()
+	if !ok {
+		return 1 << 30
+	}
+	return window.FocusHistoryID
+}
+
 func (l *List) SearchWindow(address string) (*item.Item, *ipc.Client, error) {
 	for _, item := range l.list {
 		win, exist := item.Windows[address]
@@ -2,58 +2,43 @@ package layerinfo
 
 import (
 	"fmt"
-	"hypr-dock/pkg/ipc"
 
-	"github.com/goccy/go-json"
+	"hypr-dock/pkg/ipc"
 )
 
-type Layer struct {
-	Address   string `json:"address"`
-	X         int    `json:"x"`
-	Y         int    `json:"y"`
-	W         int    `json:"w"`
-	H         int    `json:"h"`
-	Namespace string `json:"namespace"`
-	Pid       int    `json:"pid"`
-
-	Monitor string
-	Layer   string
-}
-
-type Monitor struct {
-	Levels map[string][]Layer `json:"levels"`
-}
+type Layer = ipc.Layer
 
 func GetDock() (*Layer, error) {
 	return Get("hypr-dock")
 }
 
-func Get(namespace string) (*Layer, error) {
-	jsonData, err := ipc.Hyprctl("j/layers")
-	if err != nil {
-		return nil, err
+// GetDockOn returns the dock on the monitor, an empty monitor means any dock
+func GetDockOn(monitor string) (*Layer, error) {
+	if monitor == "" {
+		return GetDock()
 	}
 
-	var monitors map[string]Monitor
-
-	err = json.Unmarshal(jsonData, &monitors)
+	layers, err := ipc.CachedLayers()
 	if err != nil {
 		return nil, err
 	}
 
-	for monitorName, monitor := range monitors {
-		for layerName, layers := range monitor.Levels {
-			for _, layer := range layers {
-				if layer.Namespace == namespace {
-					layer.Monitor = monitorName
-					layer.Layer = layerName
-					return &layer, nil
-				}
-			}
+	for _, layer := range layers {
+		if layer.Namespace == "hypr-dock" && layer.Monitor == monitor {
+			return &layer, nil
 		}
 	}
 
-	return nil, fmt.Errorf("%s layer not found", namespace)
+	return nil, fmt.Errorf("hypr-dock layer not found on %s", monitor)
+}
+
+func Get(namespace string) (*Layer, error) {
+	layer, err := ipc.CachedLayer(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &layer, nil
 }
 
 func GetMonitor() (*ipc.Monitor, error) {
@@ -1,9 +1,12 @@
 package layering
 
 import (
+	"fmt"
+
 	detectzone "hypr-dock/internal/detectZone"
 	"hypr-dock/internal/pkg/timer"
 	"hypr-dock/internal/settings"
+	"hypr-dock/pkg/dockIPC"
 
 	"github.com/dlasky/gotk3-layershell/layershell"
 	"github.com/gotk3/gotk3/gdk"
@@ -20,10 +23,18 @@ type Control struct {
 	smartLeave glib.SignalHandle
 
 	hideTimer *timer.Timer
-	special   bool
+	// open special workspace of every monitor
+	specials map[string]string
+
+	layer     string
+	exclusive bool
+	smartView bool
+	baseMode  string
+	visible   bool
 
 	orientation gtk.Orientation
 	edge        layershell.LayerShellEdgeFlags
+	monitor     *gdk.Monitor
 
 	layers map[string]layershell.LayerShellLayerFlags
 }
@@ -36,13 +47,21 @@ func New(window *gtk.Window, settings *settings.Settings) *Control {
 		"overlay":    layershell.LAYER_SHELL_LAYER_OVERLAY,
 	}
 
-	return &Control{
+	c := &Control{
 		window:   window,
 		settings: settings,
 
 		layers:    layers,
 		hideTimer: timer.New(),
+		specials:  map[string]string{},
+
+		layer:     settings.Layer,
+		exclusive: settings.Exclusive,
+		smartView: settings.SmartView,
 	}
+	c.baseMode = c.GetMode()
+
+	return c
 }
 
 func (c *Control) Init() {
@@ -59,19 +78,136 @@ func NewInit(window *gtk.Window, settings *settings.Settings) *Control {
 	return ctrl
 }
 
+// Reload re-applies the position and the layer mode from the settings
+func (c *Control) Reload() {
+	c.layer = c.settings.Layer
+	c.exclusive = c.settings.Exclusive
+	c.smartView = c.settings.SmartView
+	c.baseMode = c.GetMode()
+
+	c.SetPosition()
+	c.SetLayer()
+}
+
 func (c *Control) SetLayer() {
 	c.clear()
 
-	if c.settings.SmartView {
+	dockIPC.SendEvent("layermode", c.GetMode())
+
+	if c.smartView {
 		c.smart()
+		c.setVisible(false)
 		return
 	}
 
-	if c.settings.Exclusive {
+	if c.exclusive {
 		layershell.AutoExclusiveZoneEnable(c.window)
 	}
 
-	layershell.SetLayer(c.window, c.layers[c.settings.Layer])
+	layershell.SetLayer(c.window, c.layers[c.layer])
+	c.setVisible(true)
+}
+
+// Modes accepted by SetMode
+const (
+	ModeExclusive = "exclusive"
+	ModeSmart     = "smart"
+	ModeNormal    = "normal"
+)
+
+// ValidateMode returns the error SetMode gives for an unknown mode
+func ValidateMode(mode string) error {
+	switch mode {
+	case ModeExclusive, ModeSmart, ModeNormal:
+		return nil
+	}
+	return fmt.Errorf("unknown layer mode %q (%s, %s, %s)", mode, ModeExclusive, ModeSmart, ModeNormal)
+}
+
+// SetMode switches the running dock between exclusive zone, SmartView and plain layer
+func (c *Control) SetMode(mode string) error {
+	if err := ValidateMode(mode); err != nil {
+		return err
+	}
+
+	switch mode {
+	case ModeExclusive:
+		c.exclusive = true
+		c.smartView = false
+	case ModeSmart:
+		c.smartView = true
+	case ModeNormal:
+		c.exclusive = false
+		c.smartView = false
+	}
+
+	c.SetLayer()
+	return nil
+}
+
+// ToggleMode switches between the configured mode and the given one
+func (c *Control) ToggleMode(mode string) error {
+	target, err := c.ToggleTarget(mode)
+	if err != nil {
+		return err
+	}
+	return c.SetMode(target)
+}
+
+// ToggleTarget returns the mode ToggleMode would switch to, without switching
+func (c *Control) ToggleTarget(mode string) (string, error) {
+	if mode == "" {
+		mode = ModeSmart
+		if c.baseMode == ModeSmart {
+			mode = ModeExclusive
+		}
+	}
+	if err := ValidateMode(mode); err != nil {
+		return "", err
+	}
+
+	if c.GetMode() == mode {
+		// there is nothing to switch back to
+		if mode == c.baseMode {
+			return "", fmt.Errorf("%s is already the configured layer mode", mode)
+		}
+		mode = c.baseMode
+	}
+
+	return mode, nil
+}
+
+func (c *Control) GetMode() string {
+	if c.smartView {
+		return ModeSmart
+	}
+	if c.exclusive {
+		return ModeExclusive
+	}
+	return ModeNormal
+}
+
+// ValidateLayerName returns the error SetLayerName gives for an unknown layer
+func (c *Control) ValidateLayerName(layer string) error {
+	if _, exist := c.layers[layer]; !exist {
+		return fmt.Errorf("unknown layer %q", layer)
+	}
+	return nil
+}
+
+// SetLayerName changes the layer used outside of SmartView
+func (c *Control) SetLayerName(layer string) error {
+	if err := c.ValidateLayerName(layer); err != nil {
+		return err
+	}
+
+	c.layer = layer
+	c.SetLayer()
+	return nil
+}
+
+func (c *Control) GetLayerName() string {
+	return c.layer
 }
 
 func (c *Control) SetPosition() {
@@ -91,6 +227,11 @@ func (c *Control) SetPosition() {
 
 	position := c.settings.Position
 
+	// the position can change on reload, drop the previous anchor
+	for _, edge := range edges {
+		layershell.SetAnchor(c.window, edge, false)
+	}
+
 	layershell.SetAnchor(c.window, edges[position], true)
 	layershell.SetMargin(c.window, edges[position], 0)
 
@@ -103,6 +244,9 @@ func (c *Control) smart() {
 
 	da, err := detectzone.New(c.window, c.settings)
 	if err == nil {
+		if c.monitor != nil {
+			layershell.SetMonitor(da.Window, c.monitor)
+		}
 		da.OnEnter(func() {
 			c.SendFocus()
 		})
@@ -113,7 +257,7 @@ func (c *Control) smart() {
 	}
 
 	c.smartEnter = c.window.Connect("enter-notify-event", func(_ *gtk.Window, e *gdk.Event) {
-		if !is_e3e4(e) || c.special {
+		if !is_e3e4(e) || c.GetSpecial() {
 			return
 		}
 
@@ -130,6 +274,7 @@ func (c *Control) smart() {
 }
 
 func (c *Control) clear() {
+	c.hideTimer.Stop()
 	layershell.SetExclusiveZone(c.window, 0)
 
 	if c.da != nil {
@@ -139,38 +284,100 @@ func (c *Control) clear() {
 
 	if c.smartEnter > 0 {
 		c.window.HandlerDisconnect(c.smartEnter)
+		c.smartEnter = 0
 	}
 
 	if c.smartLeave > 0 {
 		c.window.HandlerDisconnect(c.smartLeave)
+		c.smartLeave = 0
 	}
 }
 
 func (c *Control) SendUnfocus() {
-	if !c.settings.SmartView {
+	if !c.smartView {
 		return
 	}
 
 	c.hideTimer.Run(c.settings.AutoHideDelay, func() {
-		layershell.SetLayer(c.window, layershell.LAYER_SHELL_LAYER_BOTTOM)
+		glib.IdleAdd(func() {
+			// SetMode or Reload may have left SmartView meanwhile
+			if !c.smartView {
+				return
+			}
+			layershell.SetLayer(c.window, layershell.LAYER_SHELL_LAYER_BOTTOM)
+			c.setVisible(false)
+		})
 	})
 }
 
 func (c *Control) SendFocus() {
-	if !c.settings.SmartView {
+	if !c.smartView {
 		return
 	}
 
 	c.hideTimer.Stop()
 	layershell.SetLayer(c.window, c.layers["top"])
+	c.setVisible(true)
+}
+
+func (c *Control) setVisible(visible bool) {
+	if c.visible == visible {
+		return
+	}
+
+	c.visible = visible
+	if visible {
+		dockIPC.SendEvent("visibility", "shown")
+	} else {
+		dockIPC.SendEvent("visibility", "hidden")
+	}
+}
+
+func (c *Control) GetVisible() bool {
+	return c.visible
+}
+
+// Destroy removes the detect zone and the timers of the dock before its window is destroyed
+func (c *Control) Destroy() {
+	c.clear()
 }
 
-func (c *Control) SetSpecial(is bool) {
-	c.special = is
+// SetMonitor moves the dock and its detect zone to the monitor
+func (c *Control) SetMonitor(monitor *gdk.Monitor) {
+	c.monitor = monitor
+	layershell.SetMonitor(c.window, monitor)
+
+	if c.da != nil {
+		layershell.SetMonitor(c.da.Window, monitor)
+	}
 }
 
+func (c *Control) GetMonitor() *gdk.Monitor {
+	return c.monitor
+}
+
+// SetSpecial records the special workspace (e.g. "special:term") open on the monitor,
+// an empty workspace means it was closed
+func (c *Control) SetSpecial(monitor string, workspace string) {
+	if workspace == "" {
+		delete(c.specials, monitor)
+		return
+	}
+	c.specials[monitor] = workspace
+}
+
+// GetSpecial tells if any special workspace is open
 func (c *Control) GetSpecial() bool {
-	return c.special
+	return len(c.specials) > 0
+}
+
+// GetSpecialWorkspaces returns the open special workspaces by monitor
+func (c *Control) GetSpecialWorkspaces() map[string]string {
+	specials := make(map[string]string, len(c.specials))
+	for monitor, workspace := range c.specials {
+		specials[monitor] = workspace
+	}
+	return specials
 }
 
 func (c *Control) GetOrientation() gtk.Orientation {
@@ -9,16 +9,26 @@ import (
 )
 
 type General struct {
-	CurrentTheme  string `def:"lotos"`
-	IconSize      int    `def:"23"`
-	Layer         string `def:"top" valid:"background,bottom,top,overlay"`
-	Exclusive     bool   `def:"true"`
-	SmartView     bool   `def:"false"`
-	Position      string `def:"bottom" valid:"top,bottom,left,right"`
-	AutoHideDelay int    `def:"400"`
-	SystemGapUsed bool   `def:"true"`
-	Margin        int    `def:"8"`
-	ContextPos    int    `def:"5"`
+	CurrentTheme    string `def:"lotos"`
+	IconSize        int    `def:"23"`
+	Layer           string `def:"top" valid:"background,bottom,top,overlay"`
+	Exclusive       bool   `def:"true"`
+	SmartView       bool   `def:"false"`
+	Position        string `def:"bottom" valid:"top,bottom,left,right"`
+	AutoHideDelay   int    `def:"400"`
+	SystemGapUsed   bool   `def:"true"`
+	Margin          int    `def:"8"`
+	ContextPos      int    `def:"5"`
+	AutoReload      bool   `def:"true"`
+	UrgentAnimation string `def:"bounce" valid:"none,bounce,pulse"`
+	SpecialWindows  string `def:"show" valid:"show,mark,hide"`
+	// Monitors that get a dock, "all" or names like DP-1, empty for a single dock
+	Monitors       []string `def:""`
+	MonitorWindows string   `def:"all" valid:"all,own"`
+	FollowMonitor  bool     `def:"false"`
+	PinOnDrag      bool     `def:"false"`
+	ItemOrder      string   `def:"launch" valid:"launch,name,workspace,focus"`
+	GroupMode      string   `def:"class" valid:"class,workspace,none"`
 }
 
 type Preview struct {
@@ -54,7 +64,8 @@ type Config struct {
 	ThemeConf string
 }
 
-func New(configPath string, themesDir string, logger hclog.Logger) (*Config, error) {
+// New reads the main config and its theme, a non-empty theme overrides CurrentTheme
+func New(configPath string, themesDir string, theme string, logger hclog.Logger) (*Config, error) {
 	var err error
 
 	// MAIN CONFIG
@@ -67,17 +78,21 @@ func New(configPath string, themesDir string, logger hclog.Logger) (*Config, err
 	}
 
 	// THEME
+	if theme != "" {
+		config.CurrentTheme = theme
+	}
+
 	themeDir := filepath.Join(themesDir, config.CurrentTheme)
 	themeConf := filepath.Join(themeDir, "theme.conf")
 	th := ini.New(themeConf, logger)
 
-	var theme Theme
-	err = th.Unmarshal(&theme)
+	var themeConfig Theme
+	err = th.Unmarshal(&themeConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	config.Theme = theme
+	config.Theme = themeConfig
 	config.ThemeDir = themeDir
 	config.ThemeConf = themeConf
 
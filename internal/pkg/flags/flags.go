@@ -10,6 +10,11 @@ type Flags struct {
 	Config   string
 	Theme    string
 	LogLevel string
+
+	// forwarded to the running instance
+	Reload bool
+	Quit   bool
+	Toggle bool
 }
 
 func Get() Flags {
@@ -17,8 +22,11 @@ func Get() Flags {
 
 	dev := flag.Bool("dev", false, "enable developer mode")
 	config := flag.String("config", "~/.config/hypr-dock", "config file")
-	theme := flag.String("theme", "", "theme dir")
+	theme := flag.String("theme", "", "theme name, overrides CurrentTheme")
 	logLevel := flag.String("log-level", "info", "log level")
+	reload := flag.Bool("reload", false, "reload the config of the running dock")
+	quit := flag.Bool("quit", false, "close the running dock")
+	toggle := flag.Bool("toggle", false, "close the running dock or start a new one")
 	flag.Parse()
 
 	return Flags{
@@ -26,5 +34,8 @@ func Get() Flags {
 		Config:   *config,
 		Theme:    *theme,
 		LogLevel: *logLevel,
+		Reload:   *reload,
+		Quit:     *quit,
+		Toggle:   *toggle,
 	}
 }
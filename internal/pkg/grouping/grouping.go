@@ -0,0 +1,130 @@
+// Package grouping sorts the Hyprland windows into the dock items
+// and the switcher workspaces, without GTK
+package grouping
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"hypr-dock/internal/pkg/conf"
+	"hypr-dock/pkg/ipc"
+)
+
+var firstWord = regexp.MustCompile(`^[a-zA-Z-]+`)
+
+// ClassName returns the class of the window,
+// or the first word of its title for the apps that set none
+func ClassName(client ipc.Client) string {
+	if client.Class == "" {
+		return strings.ToLower(firstWord.FindString(client.InitialTitle))
+	}
+	return client.Class
+}
+
+// ItemKey returns the key of the item the window belongs to in the GroupMode:
+// the class, the class on the workspace or the window itself
+func ItemKey(client ipc.Client, groupMode string) string {
+	className := ClassName(client)
+
+	switch groupMode {
+	case "workspace":
+		return fmt.Sprintf("%s@%d", className, client.Workspace.Id)
+	case "none":
+		return className + "@" + client.Address
+	}
+	return className
+}
+
+// Hidden tells if the window is kept out of the dock by SpecialWindows = hide
+// or, with MonitorWindows = own, is on another monitor than the dock one (-1 if unknown)
+func Hidden(client ipc.Client, general conf.General, monitor int) bool {
+	if general.SpecialWindows == "hide" && client.InSpecialWorkspace() {
+		return true
+	}
+
+	if general.MonitorWindows == "own" {
+		return monitor >= 0 && client.Monitor != monitor
+	}
+
+	return false
+}
+
+// KeepsLauncher tells if the item stays without windows,
+// only the item keyed by the class is the launcher of a pinned app
+func KeepsLauncher(key string, className string, pinned []string) bool {
+	return key == className && slices.Contains(pinned, className)
+}
+
+// ByWorkspace orders the mapped windows of the regular workspaces for the switcher:
+// the workspaces by their most recent window, the windows inside by focus.
+// members lists the indexes of the windows of every workspace.
+// A monitor other than -1 keeps only its windows.
+func ByWorkspace(all []ipc.Client, monitor int) (clients []ipc.Client, workspaces []int, members map[int][]int) {
+	members = make(map[int][]int)
+
+	// Group clients by workspace temporarily to calculate recency
+	tempWsMap := make(map[int][]ipc.Client)
+	minFocusMap := make(map[int]int) // Workspace ID -> Minimum FocusHistoryID (Lower = More Recent)
+
+	for _, c := range all {
+		if monitor != -1 && c.Monitor != monitor {
+			continue
+		}
+
+		if c.Mapped && c.Workspace.Id > 0 {
+			tempWsMap[c.Workspace.Id] = append(tempWsMap[c.Workspace.Id], c)
+
+			// Update minimum focus ID for this workspace
+			currMin, exists := minFocusMap[c.Workspace.Id]
+			if !exists || c.FocusHistoryID < currMin {
+				minFocusMap[c.Workspace.Id] = c.FocusHistoryID
+			}
+		}
+	}
+
+	for k := range tempWsMap {
+		workspaces = append(workspaces, k)
+	}
+
+	// Lower MinFocusID = Workspace was active more recently
+	sort.Slice(workspaces, func(i, j int) bool {
+		return minFocusMap[workspaces[i]] < minFocusMap[workspaces[j]]
+	})
+
+	for _, wsID := range workspaces {
+		clientsInWs := tempWsMap[wsID]
+
+		// Sort clients WITHIN workspace by FocusHistoryID (MRU within workspace)
+		sort.Slice(clientsInWs, func(i, j int) bool {
+			return clientsInWs[i].FocusHistoryID < clientsInWs[j].FocusHistoryID
+		})
+
+		for _, c := range clientsInWs {
+			clients = append(clients, c)
+			members[wsID] = append(members[wsID], len(clients)-1)
+		}
+	}
+
+	return clients, workspaces, members
+}
+
+// Selection returns the index of the window the switcher selects first:
+// the first window of the previous workspace, or the previous window
+// when there is a single workspace
+func Selection(clients []ipc.Client, workspaces []int, members map[int][]int) int {
+	// Index 0 in workspaces is the current active workspace.
+	if len(workspaces) > 1 {
+		if indices := members[workspaces[1]]; len(indices) > 0 {
+			return indices[0]
+		}
+		return 0
+	}
+
+	if len(workspaces) == 1 && len(clients) > 1 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package grouping
+
+import (
+	"slices"
+	"testing"
+
+	"hypr-dock/internal/pkg/conf"
+	"hypr-dock/pkg/ipc"
+)
+
+func client(address string, class string, workspace int, monitor int, focus int) ipc.Client {
+	c := ipc.Client{
+		Address:        address,
+		Mapped:         true,
+		Class:          class,
+		Monitor:        monitor,
+		FocusHistoryID: focus,
+	}
+	c.Workspace.Id = workspace
+	return c
+}
+
+func addresses(clients []ipc.Client) []string {
+	var list []string
+	for _, c := range clients {
+		list = append(list, c.Address)
+	}
+	return list
+}
+
+func TestItemKey(t *testing.T) {
+	kitty := client("0x1", "kitty", 2, 0, 0)
+	untitled := client("0x2", "", 1, 0, 1)
+	untitled.InitialTitle = "Steam - News"
+
+	tests := []struct {
+		client    ipc.Client
+		groupMode string
+		key       string
+	}{
+		{kitty, "class", "kitty"},
+		{kitty, "workspace", "kitty@2"},
+		{kitty, "none", "kitty@0x1"},
+		{untitled, "class", "steam"},
+		{untitled, "workspace", "steam@1"},
+	}
+
+	for _, test := range tests {
+		if key := ItemKey(test.client, test.groupMode); key != test.key {
+			t.Errorf("ItemKey(%s, %s) = %q, want %q", test.client.Address, test.groupMode, key, test.key)
+		}
+	}
+}
+
+func TestHidden(t *testing.T) {
+	regular := client("0x1", "kitty", 1, 1, 0)
+	special := client("0x2", "kitty", -98, 0, 1)
+	special.Workspace.Name = "special:term"
+
+	tests := []struct {
+		name    string
+		client  ipc.Client
+		general conf.General
+		monitor int
+		hidden  bool
+	}{
+		{"shown", regular, conf.General{SpecialWindows: "show", MonitorWindows: "all"}, 0, false},
+		{"special shown", special, conf.General{SpecialWindows: "mark", MonitorWindows: "all"}, 0, false},
+		{"special hidden", special, conf.General{SpecialWindows: "hide", MonitorWindows: "all"}, 0, true},
+		{"other monitor", regular, conf.General{MonitorWindows: "own"}, 0, true},
+		{"own monitor", regular, conf.General{MonitorWindows: "own"}, 1, false},
+		{"unknown monitor", regular, conf.General{MonitorWindows: "own"}, -1, false},
+	}
+
+	for _, test := range tests {
+		if hidden := Hidden(test.client, test.general, test.monitor); hidden != test.hidden {
+			t.Errorf("%s: got %v, want %v", test.name, hidden, test.hidden)
+		}
+	}
+}
+
+func TestKeepsLauncher(t *testing.T) {
+	pinned := []string{"firefox", "kitty"}
+
+	tests := []struct {
+		key, className string
+		keep           bool
+	}{
+		{"kitty", "kitty", true},
+		{"kitty@2", "kitty", false},
+		{"kitty@0x1", "kitty", false},
+		{"foot", "foot", false},
+	}
+
+	for _, test := range tests {
+		if keep := KeepsLauncher(test.key, test.className, pinned); keep != test.keep {
+			t.Errorf("KeepsLauncher(%q, %q) = %v", test.key, test.className, keep)
+		}
+	}
+}
+
+func TestByWorkspace(t *testing.T) {
+	unmapped := client("0x6", "steam", 1, 0, 5)
+	unmapped.Mapped = false
+
+	all := []ipc.Client{
+		client("0x1", "kitty", 1, 0, 2),
+		client("0x2", "firefox", 2, 0, 1),
+		client("0x3", "foot", 1, 0, 0),
+		client("0x4", "mpv", 3, 1, 3),
+		client("0x5", "term", -98, 0, 4),
+		unmapped,
+	}
+
+	clients, workspaces, members := ByWorkspace(all, -1)
+	if got := addresses(clients); !slices.Equal(got, []string{"0x3", "0x1", "0x2", "0x4"}) {
+		t.Fatalf("clients %v", got)
+	}
+	if !slices.Equal(workspaces, []int{1, 2, 3}) {
+		t.Fatalf("workspaces %v", workspaces)
+	}
+	if !slices.Equal(members[1], []int{0, 1}) || !slices.Equal(members[3], []int{3}) {
+		t.Fatalf("members %v", members)
+	}
+	if selected := Selection(clients, workspaces, members); selected != 2 {
+		t.Fatalf("selected %d, want the first window of workspace 2", selected)
+	}
+
+	clients, workspaces, members = ByWorkspace(all, 1)
+	if got := addresses(clients); !slices.Equal(got, []string{"0x4"}) {
+		t.Fatalf("clients of monitor 1 %v", got)
+	}
+	if selected := Selection(clients, workspaces, members); selected != 0 {
+		t.Fatalf("selected %d with a single window", selected)
+	}
+}
+
+func TestSelectionSingleWorkspace(t *testing.T) {
+	clients, workspaces, members := ByWorkspace([]ipc.Client{
+		client("0x1", "kitty", 1, 0, 1),
+		client("0x2", "foot", 1, 0, 0),
+	}, -1)
+
+	if selected := Selection(clients, workspaces, members); selected != 1 || clients[1].Address != "0x1" {
+		t.Fatalf("selected %d, want the previous window", selected)
+	}
+	if selected := Selection(nil, nil, nil); selected != 0 {
+		t.Fatalf("selected %d without windows", selected)
+	}
+}
@@ -42,6 +42,30 @@ func New(instances int, settings *settings.Settings) (*gtk.Image, error) {
 	return utils.CreateImageWidthTransform(path, settings.IconSize, 0.56, rotate)
 }
 
+// NewUrgent creates the indicator of an item whose window demands attention
+// from point/urgent.*, the theme may not provide one
+func NewUrgent(settings *settings.Settings) (*gtk.Image, error) {
+	dirPath := filepath.Join(settings.ThemeDir, "point")
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indicator directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		if entry.IsDir() || !isSupportedExtension(ext) || name[:len(name)-len(ext)] != "urgent" {
+			continue
+		}
+
+		rotate := settings.Position == "left" || settings.Position == "right"
+		return utils.CreateImageWidthTransform(filepath.Join(dirPath, name), settings.IconSize, 0.56, rotate)
+	}
+
+	return nil, errors.New("urgent indicator not found")
+}
+
 // selectIndicatorFile chooses the appropriate indicator file based on instances count
 func selectIndicatorFile(instances int, files []IndicatorFile) IndicatorFile {
 	selected := files[0]
@@ -6,12 +6,20 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 )
 
+var reloadHandler func()
+
+// OnReload sets the handler called in the main loop on SIGHUP
+func OnReload(handler func()) {
+	reloadHandler = handler
+}
+
 func Handler() {
 	signalChanel := make(chan os.Signal, 1)
-	signal.Notify(signalChanel, syscall.SIGTERM, syscall.SIGUSR1)
+	signal.Notify(signalChanel, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGHUP)
 
 	go func() {
 		for {
@@ -23,6 +31,11 @@ func Handler() {
 			case syscall.SIGUSR1:
 				log.Println("Exit... (SIGUSR1)")
 				gtk.MainQuit()
+			case syscall.SIGHUP:
+				log.Println("Reload... (SIGHUP)")
+				if reloadHandler != nil {
+					glib.IdleAdd(reloadHandler)
+				}
 			default:
 				log.Println("Unknow signal")
 			}
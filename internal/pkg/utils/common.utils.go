@@ -1,9 +1,6 @@
 package utils
 
 import (
-	"regexp"
-	"strings"
-
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -28,10 +25,3 @@ func СreateLogger(logLevel string) hclog.Logger {
 		Color: hclog.AutoColor,
 	})
 }
-
-func NormaliseTitle(title string) string {
-	re := regexp.MustCompile(`^[a-zA-Z-]+`)
-	firstWord := re.FindString(title)
-
-	return strings.ToLower(firstWord)
-}
@@ -84,19 +84,19 @@ func AddStyle(widget gtk.IWidget, style string) (*gtk.CssProvider, error) {
 	return provider, nil
 }
 
-func AddCssProvider(cssFile string) error {
+func AddCssProvider(cssFile string) (*gtk.CssProvider, error) {
 	cssProvider, err := gtk.CssProviderNew()
 	if err != nil {
-		return errors.Wrap(err, "failed to create CSS provider")
+		return nil, errors.Wrap(err, "failed to create CSS provider")
 	}
 
 	if err := cssProvider.LoadFromPath(cssFile); err != nil {
-		return errors.Wrapf(err, "failed to load CSS from %q", cssFile)
+		return nil, errors.Wrapf(err, "failed to load CSS from %q", cssFile)
 	}
 
 	screen, err := gdk.ScreenGetDefault()
 	if err != nil {
-		return errors.Wrap(err, "failed to get default screen")
+		return nil, errors.Wrap(err, "failed to get default screen")
 	}
 
 	gtk.AddProviderForScreen(
@@ -104,6 +104,20 @@ func AddCssProvider(cssFile string) error {
 		gtk.STYLE_PROVIDER_PRIORITY_APPLICATION,
 	)
 
+	return cssProvider, nil
+}
+
+func RemoveCssProvider(cssProvider *gtk.CssProvider) error {
+	if cssProvider == nil {
+		return errors.New("provider is nil")
+	}
+
+	screen, err := gdk.ScreenGetDefault()
+	if err != nil {
+		return errors.Wrap(err, "failed to get default screen")
+	}
+
+	gtk.RemoveProviderForScreen(screen, cssProvider)
 	return nil
 }
 
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher polls the modification time of a set of files
+// and calls the handler once per detected change
+type Watcher struct {
+	interval time.Duration
+	handler  func()
+	mtimes   map[string]time.Time
+	stop     chan struct{}
+	mu       sync.Mutex
+}
+
+func New(interval time.Duration, handler func(), paths ...string) *Watcher {
+	w := &Watcher{
+		interval: interval,
+		handler:  handler,
+		stop:     make(chan struct{}),
+	}
+
+	w.SetPaths(paths...)
+	go w.run()
+
+	return w
+}
+
+// SetPaths replaces the watched files, e.g. after the theme was switched
+func (w *Watcher) SetPaths(paths ...string) {
+	mtimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		mtimes[path] = modTime(path)
+	}
+
+	w.mu.Lock()
+	w.mtimes = mtimes
+	w.mu.Unlock()
+}
+
+func (w *Watcher) Stop() {
+	close(w.stop)
+}
+
+func (w *Watcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if w.changed() {
+				w.handler()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changed := false
+	for path, prev := range w.mtimes {
+		current := modTime(path)
+		if !current.Equal(prev) {
+			w.mtimes[path] = current
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
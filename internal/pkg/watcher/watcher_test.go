@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func touch(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitChange(t *testing.T, changes <-chan struct{}, want bool) {
+	t.Helper()
+
+	select {
+	case <-changes:
+		if !want {
+			t.Fatal("handler called without a change")
+		}
+	case <-time.After(100 * time.Millisecond):
+		if want {
+			t.Fatal("change not detected")
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "hypr-dock.conf")
+	theme := filepath.Join(dir, "style.css")
+	start := time.Now().Add(-time.Hour)
+	touch(t, config, start)
+	touch(t, theme, start)
+
+	changes := make(chan struct{}, 4)
+	w := New(5*time.Millisecond, func() {
+		changes <- struct{}{}
+	}, config)
+	defer w.Stop()
+
+	waitChange(t, changes, false)
+
+	touch(t, config, start.Add(time.Minute))
+	waitChange(t, changes, true)
+	waitChange(t, changes, false)
+
+	// a removed file is a change too, e.g. an editor replacing it
+	if err := os.Remove(config); err != nil {
+		t.Fatal(err)
+	}
+	waitChange(t, changes, true)
+
+	w.SetPaths(theme)
+	touch(t, config, start.Add(2*time.Minute))
+	waitChange(t, changes, false)
+
+	touch(t, theme, start.Add(time.Minute))
+	waitChange(t, changes, true)
+}
@@ -7,6 +7,7 @@ import (
 	"hypr-dock/internal/pkg/timer"
 	"hypr-dock/internal/pvwidget"
 	"hypr-dock/internal/settings"
+	"hypr-dock/pkg/dockIPC"
 	"hypr-dock/pkg/ipc"
 
 	// "log"
@@ -26,8 +27,11 @@ type PV struct {
 	hideTimer *timer.Timer
 	moveTimer *timer.Timer
 
+	// keys of the items, see item.Item.Key
 	className    string
 	preClassName string
+	// class of the shown item for the dock events
+	class string
 
 	popup    *popup.Popup
 	widget   *pvwidget.Widget
@@ -93,17 +97,21 @@ func (pv *PV) Show(item *item.Item) {
 	}
 
 	pv.preClassName = pv.className
-	pv.className = item.ClassName
+	pv.className = item.Key
+	pv.class = item.ClassName
 
 	glib.IdleAdd(func() {
 		pv.show(item)
 	})
 	pv.SetActive(true)
+
+	dockIPC.SendEvent("previewopen", item.ClassName)
 }
 
 func (pv *PV) Change(item *item.Item) {
 	pv.preClassName = pv.className
-	pv.className = item.ClassName
+	pv.className = item.Key
+	pv.class = item.ClassName
 
 	glib.IdleAdd(func() {
 		pv.change(item)
@@ -119,6 +127,8 @@ func (pv *PV) Hide() {
 		pv.hide()
 	})
 	pv.SetActive(false)
+
+	dockIPC.SendEvent("previewclose", pv.class)
 }
 
 func (pv *PV) show(item *item.Item) {
@@ -161,13 +171,17 @@ func (pv *PV) show(item *item.Item) {
 			return pv.popupWinSet(window)
 		})
 
-		target, orig := pv.prepareCord(w, h, item)
+		target, orig, err := pv.prepareCord(w, h, item)
+		if err != nil {
+			pv.log.Error("Failed to get item button cords", "error", err)
+			return
+		}
 		if orig.Monitor != nil {
 			pv.popup.SetMonitor(orig.Monitor)
 		}
 
 		pv.popup.Set(widget)
-		err := pv.popup.Open(target.x, target.y, target.anchorX, target.anchorY)
+		err = pv.popup.Open(target.x, target.y, target.anchorX, target.anchorY)
 		if err != nil {
 			pv.log.Error("Failed to open preview popup", "error", err)
 		}
@@ -221,7 +235,11 @@ func (pv *PV) change(item *item.Item) {
 			return nil
 		})
 
-		target, _ := pv.prepareCord(w, h, item)
+		target, _, err := pv.prepareCord(w, h, item)
+		if err != nil {
+			pv.log.Error("Failed to get item button cords", "error", err)
+			return
+		}
 		pv.popup.Set(widget)
 		pv.popup.Move(target.x, target.y)
 	})
@@ -270,6 +288,7 @@ func (pv *PV) resize(item *item.Item, w, h int) {
 		cord, err := item.GetCord()
 		if err != nil {
 			pv.log.Error("Failed to get item button cords", "error", err)
+			return
 		}
 
 		// move
@@ -280,6 +299,13 @@ func (pv *PV) resize(item *item.Item, w, h int) {
 	}
 }
 
+// SetFocused marks the window in the open preview
+func (pv *PV) SetFocused(address string) {
+	if pv.active && pv.widget != nil {
+		pv.widget.SetFocused(address)
+	}
+}
+
 func (pv *PV) OnEnter(handler func(w *gtk.Window, e *gdk.Event)) {
 	pv.onEnter = handler
 }
@@ -317,10 +343,10 @@ type popupTarget struct {
 	anchorX, anchorY string
 }
 
-func (pv *PV) prepareCord(w, h int, item *item.Item) (target popupTarget, orig *item.Position) {
-	orig, err := item.GetCord()
+func (pv *PV) prepareCord(w, h int, item *item.Item) (target popupTarget, orig *item.Position, err error) {
+	orig, err = item.GetCord()
 	if err != nil {
-		pv.log.Error("Failed to get item button cords", "error", err)
+		return target, nil, err
 	}
 
 	target = popupTarget{}
@@ -357,5 +383,5 @@ func (pv *PV) prepareCord(w, h int, item *item.Item) (target popupTarget, orig *
 		target.y -= geo.GetY()
 	}
 
-	return target, orig
+	return target, orig, nil
 }
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"hypr-dock/internal/hysc"
 	"hypr-dock/internal/item"
+	"hypr-dock/internal/pkg/timer"
 	"hypr-dock/internal/pkg/utils"
 	"hypr-dock/internal/settings"
 	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/dispatch"
 
 	"sync"
 
@@ -17,6 +19,9 @@ import (
 	"github.com/hashicorp/go-hclog"
 )
 
+// how long a file is held over a window before it is focused
+const dropFocusDelay = 700
+
 type Widget struct {
 	readyCount    int
 	expectedCount int
@@ -32,6 +37,8 @@ type Widget struct {
 	onClick  func(*ipc.Client)
 	onEmpty  func()
 
+	windowBoxes map[string]*gtk.Box
+
 	log hclog.Logger
 
 	*gtk.Box
@@ -45,11 +52,12 @@ func New(item *item.Item, settings *settings.Settings, log hclog.Logger) (*Widge
 	wrapper.SetName("pv-wrap")
 
 	widget := &Widget{
-		Box:      wrapper,
-		settings: settings,
-		item:     item,
-		onReady:  func(w, h int) { log.Trace("PV Widget ready", "width", w, "height", h) },
-		onResize: func(w, h int) { log.Trace("PV Widget resize", "width", w, "height", h) },
+		Box:         wrapper,
+		settings:    settings,
+		item:        item,
+		windowBoxes: map[string]*gtk.Box{},
+		onReady:     func(w, h int) { log.Trace("PV Widget ready", "width", w, "height", h) },
+		onResize:    func(w, h int) { log.Trace("PV Widget resize", "width", w, "height", h) },
 
 		log: log,
 	}
@@ -141,17 +149,22 @@ func (w *Widget) createWindowWidget(window *ipc.Client) error {
 	utils.AddStyle(closeBtn, "#close-btn {padding: 0;}")
 
 	eventBox.Connect("button-press-event", func(eb *gtk.EventBox, e *gdk.Event) {
-		go ipc.Hyprctl("dispatch focuswindow address:" + window.Address)
+		go w.runDispatch(dispatch.FocusWindow(window.Address))
 
 		if w.onClick != nil {
 			w.onClick(window)
 		}
 	})
+	w.initDrop(eventBox, window)
 
 	context, err := windowBox.GetStyleContext()
 	if err == nil {
 		utils.SetAutoHover(eventBox.ToWidget(), context)
+		if window.Address == w.item.Focused() {
+			context.AddClass("focused")
+		}
 	}
+	w.windowBoxes[window.Address] = windowBox
 	utils.SetCursorPointer(eventBox.ToWidget())
 
 	var stream *hysc.Stream
@@ -171,7 +184,7 @@ func (w *Widget) createWindowWidget(window *ipc.Client) error {
 		}
 
 		closeBtn.Connect("button-press-event", func() {
-			go ipc.Hyprctl("dispatch closewindow address:" + window.Address)
+			go w.runDispatch(dispatch.CloseWindow(window.Address))
 			if len(w.item.Windows) == 1 {
 				w.onEmpty()
 				return
@@ -184,6 +197,7 @@ func (w *Widget) createWindowWidget(window *ipc.Client) error {
 
 			w.onResize(w.totalWidth, w.commonHeight)
 
+			delete(w.windowBoxes, window.Address)
 			windowBox.Destroy()
 			w.ShowAll()
 		})
@@ -232,6 +246,50 @@ func (w *Widget) createWindowWidget(window *ipc.Client) error {
 	return nil
 }
 
+// initDrop focuses the window when a file is held over or dropped on its preview,
+// so that the file can then be dropped into the window
+func (w *Widget) initDrop(eventBox *gtk.EventBox, window *ipc.Client) {
+	target, err := gtk.TargetEntryNew("text/uri-list", gtk.TARGET_OTHER_APP, 0)
+	if err != nil {
+		w.log.Error("Unable to create drag target", "error", err)
+		return
+	}
+	eventBox.DragDestSet(gtk.DEST_DEFAULT_ALL, []gtk.TargetEntry{*target}, gdk.ACTION_COPY)
+
+	spring := timer.New()
+	// the window is focused once per hover
+	fired := false
+	focus := func() {
+		go w.runDispatch(dispatch.FocusWindow(window.Address))
+
+		if w.onClick != nil {
+			w.onClick(window)
+		}
+	}
+
+	eventBox.Connect("drag-motion", func() bool {
+		if fired {
+			return false
+		}
+		fired = true
+
+		spring.Run(dropFocusDelay, func() {
+			glib.IdleAdd(focus)
+		})
+		return false
+	})
+
+	eventBox.Connect("drag-leave", func() {
+		spring.Stop()
+		fired = false
+	})
+
+	eventBox.Connect("drag-data-received", func() {
+		spring.Stop()
+		focus()
+	})
+}
+
 func (w *Widget) OnResize(handler func(w, h int)) {
 	w.onResize = handler
 }
@@ -248,6 +306,29 @@ func (w *Widget) OnEmpty(handler func()) {
 	w.onEmpty = handler
 }
 
+func (w *Widget) runDispatch(cmd dispatch.Command) {
+	if err := dispatch.Run(cmd); err != nil {
+		w.log.Error("Dispatch failed", "command", cmd.String(), "error", err)
+	}
+}
+
+// SetFocused moves the "focused" class to the preview of the window
+func (w *Widget) SetFocused(address string) {
+	for windowAddress, windowBox := range w.windowBoxes {
+		context, err := windowBox.GetStyleContext()
+		if err != nil {
+			continue
+		}
+
+		if windowAddress == address {
+			context.AddClass("focused")
+		} else {
+			context.RemoveClass("focused")
+		}
+	}
+}
+
+// GetClass returns the key of the item, the class with GroupMode = class
 func (w *Widget) GetClass() string {
-	return w.item.ClassName
+	return w.item.Key
 }
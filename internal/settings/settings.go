@@ -6,6 +6,7 @@ import (
 	"hypr-dock/internal/pkg/pinned"
 	"hypr-dock/internal/pkg/utils"
 
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,6 +26,9 @@ type Settings struct {
 	ThemesDir  string
 	ThemeStyle string
 	PinnedApps []string
+
+	// theme set with --theme, takes precedence over CurrentTheme
+	ThemeOverride string
 }
 
 func Init(flags flags.Flags, log hclog.Logger) (*Settings, error) {
@@ -54,7 +58,7 @@ func Init(flags flags.Flags, log hclog.Logger) (*Settings, error) {
 	themesDir := filepath.Join(configDir, "themes")
 
 	// read main config and current theme config
-	config, err := conf.New(configPath, themesDir, log)
+	config, err := conf.New(configPath, themesDir, flags.Theme, log)
 	if err != nil {
 		log.Error("Confog faild", "error", err)
 	}
@@ -63,17 +67,56 @@ func Init(flags flags.Flags, log hclog.Logger) (*Settings, error) {
 	themeStyle := filepath.Join(config.ThemeDir, "style.css")
 
 	return &Settings{
-		Config:     config,
-		LocalDir:   localDir,
-		ConfigDir:  configDir,
-		ConfigPath: configPath,
-		PinnedPath: pinnedPath,
-		ThemesDir:  themesDir,
-		ThemeStyle: themeStyle,
-		PinnedApps: pinnedApps,
+		Config:        config,
+		LocalDir:      localDir,
+		ConfigDir:     configDir,
+		ConfigPath:    configPath,
+		PinnedPath:    pinnedPath,
+		ThemesDir:     themesDir,
+		ThemeStyle:    themeStyle,
+		PinnedApps:    pinnedApps,
+		ThemeOverride: flags.Theme,
 	}, nil
 }
 
+// Reload re-reads the main config and the current theme in place,
+// so every holder of this Settings sees the new values.
+// Config is not guarded, Reload and every reader must run in the gtk main loop
+func (s *Settings) Reload(log hclog.Logger) error {
+	config, err := conf.New(s.ConfigPath, s.ThemesDir, s.ThemeOverride, log)
+	if err != nil {
+		return err
+	}
+
+	s.Config = config
+	s.ThemeStyle = filepath.Join(config.ThemeDir, "style.css")
+
+	return nil
+}
+
+// SetConfigPath changes the main config file, "~/" is expanded.
+// The new file is read on the next Reload
+func (s *Settings) SetConfigPath(path string) error {
+	path = expand(path)
+	if !utils.FileExists(path) {
+		return fmt.Errorf("config %q not found", path)
+	}
+
+	s.ConfigPath = path
+	return nil
+}
+
+// SetTheme forces a theme from ThemesDir, an empty name returns to CurrentTheme.
+// The theme is read on the next Reload
+func (s *Settings) SetTheme(name string) error {
+	if name != "" && !utils.DirExists(filepath.Join(s.ThemesDir, name)) {
+		return fmt.Errorf("theme %q not found in %s", name, s.ThemesDir)
+	}
+
+	s.ThemeOverride = name
+	return nil
+}
+
 func GetConfigDir(dev bool) (string, bool, error) {
 	var target, source string
 
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// docks is shared by the root state and the states of all docks
+type docks struct {
+	list []*State
+	mu   sync.Mutex
+}
+
+// NewDock creates the state of a dock on the monitor ("" lets the compositor choose),
+// it shares the settings, the logger and the list of docks with s
+func (s *State) NewDock(monitor string) *State {
+	dock := New(s.GetSettings(), s.GetLogger())
+	dock.monitor = monitor
+	dock.docks = s.docks
+
+	s.docks.mu.Lock()
+	defer s.docks.mu.Unlock()
+	s.docks.list = append(s.docks.list, dock)
+
+	return dock
+}
+
+// RemoveDock drops the dock from the list of docks
+func (s *State) RemoveDock() {
+	s.docks.mu.Lock()
+	defer s.docks.mu.Unlock()
+
+	s.docks.list = slices.DeleteFunc(s.docks.list, func(dock *State) bool {
+		return dock == s
+	})
+}
+
+// Docks returns the states of all docks sorted by monitor
+func (s *State) Docks() []*State {
+	s.docks.mu.Lock()
+	defer s.docks.mu.Unlock()
+
+	list := append([]*State{}, s.docks.list...)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GetMonitor() < list[j].GetMonitor()
+	})
+	return list
+}
+
+// GetDock returns the dock on the monitor
+func (s *State) GetDock(monitor string) *State {
+	for _, dock := range s.Docks() {
+		if dock.GetMonitor() == monitor {
+			return dock
+		}
+	}
+	return nil
+}
+
+func (s *State) GetMonitor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.monitor
+}
+
+// SetFollowedMonitor records the monitor the single dock was moved to by FollowMonitor
+func (s *State) SetFollowedMonitor(monitor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.followed = monitor
+}
+
+func (s *State) GetFollowedMonitor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.followed
+}
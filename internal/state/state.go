@@ -17,9 +17,16 @@ type State struct {
 	settings *settings.Settings
 	window   *gtk.Window
 	layerctl *layering.Control
+	appBox   *gtk.Box
 	itemsBox *gtk.Box
 	list     *itemsctl.List
 	pv       *pvctl.PV
+
+	activeWindow string
+
+	monitor  string
+	followed string
+	docks    *docks
 	mu       sync.Mutex
 }
 
@@ -29,6 +36,7 @@ func New(settings *settings.Settings, logger hclog.Logger) *State {
 		list:     itemsctl.New(),
 		pv:       pvctl.New(settings, logger),
 		logger:   logger,
+		docks:    &docks{},
 	}
 }
 
@@ -91,6 +99,18 @@ func (s *State) SetWindow(window *gtk.Window) {
 	s.window = window
 }
 
+func (s *State) SetAppBox(box *gtk.Box) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appBox = box
+}
+
+func (s *State) GetAppBox() *gtk.Box {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appBox
+}
+
 func (s *State) SetItemsBox(box *gtk.Box) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -109,6 +129,18 @@ func (s *State) GetItemsBox() *gtk.Box {
 	return s.itemsBox
 }
 
+func (s *State) SetActiveWindow(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeWindow = address
+}
+
+func (s *State) GetActiveWindow() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activeWindow
+}
+
 func (s *State) GetPV() *pvctl.PV {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3,7 +3,7 @@ package switcher
 import (
 	"fmt"
 
-	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/dispatch"
 )
 
 // cycle moves selection by direction (1 for forward, -1 for backward)
@@ -132,7 +132,9 @@ func (s *Switcher) confirm() {
 
 		// Async Call
 		go func() {
-			ipc.Hyprctl(fmt.Sprintf("dispatch focuswindow address:%s", addr))
+			if err := dispatch.Run(dispatch.FocusWindow(addr)); err != nil {
+				debugLog("CONFIRM: Focus failed: %v", err)
+			}
 		}()
 	} else {
 		s.visible = false
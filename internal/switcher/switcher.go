@@ -117,6 +117,12 @@ func Run(confPath string) {
 	s.createMainLayout()
 	logTiming("Main layout created")
 
+	// Keep the Hyprland state current between activations
+	if err := ipc.StartCache(30 * time.Second); err != nil {
+		debugLog("Error loading Hyprland state: %v", err)
+	}
+	go ipc.InitHyprEvents()
+
 	// Load data and render
 	logTiming("Loading workspace data")
 	s.loadData()
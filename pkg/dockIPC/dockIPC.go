@@ -0,0 +1,89 @@
+package dockIPC
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// SocketPath returns the control socket location of the running dock
+func SocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("hypr-dock-%s.sock", os.Getenv("USER")))
+	}
+
+	return filepath.Join(dir, "hypr-dock.sock")
+}
+
+// StartServer listens on fileName and passes every received command to handler.
+// It blocks until the listener is closed.
+func StartServer(fileName string, handler func(string) ([]byte, error)) error {
+	if err := os.RemoveAll(fileName); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", fileName)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(fileName, 0600); err != nil {
+		return err
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			continue
+		}
+
+		go handleConnection(conn, handler)
+	}
+}
+
+func handleConnection(conn net.Conn, handler func(string) ([]byte, error)) {
+	defer conn.Close()
+
+	command, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+
+	response, err := handler(strings.TrimSpace(command))
+	if err != nil {
+		response = []byte("error: " + err.Error() + "\n")
+	}
+
+	conn.Write(response)
+}
+
+// Send writes command to the socket and returns the whole reply
+func Send(fileName string, command string) ([]byte, error) {
+	conn, err := net.Dial("unix", fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(command + "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(conn)
+}
+
+func StopServer(fileName string) error {
+	return syscall.Unlink(fileName)
+}
@@ -0,0 +1,123 @@
+package dockIPC
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		request               string
+		command, action, data string
+		json                  bool
+	}{
+		{"", "", "", "", false},
+		{"help", "help", "", "", false},
+		{"layer toggle", "layer", "toggle", "", false},
+		{"j/pinned list", "pinned", "list", "", true},
+		{"window title  --- draft --- ", "window", "title", "--- draft ---", false},
+		{"j/ item activate firefox", "item", "activate", "firefox", true},
+	}
+
+	for _, test := range tests {
+		command, action, data, json := ParseRequest(test.request)
+		if command != test.command || action != test.action || data != test.data || json != test.json {
+			t.Errorf("ParseRequest(%q) = %q, %q, %q, %v", test.request, command, action, data, json)
+		}
+	}
+}
+
+func TestReply(t *testing.T) {
+	type item struct {
+		Class   string   `json:"class"`
+		Windows []string `json:"windows"`
+		Hidden  string   `json:"-"`
+	}
+	data := []item{{Class: "kitty", Windows: []string{"0x1", "0x2"}, Hidden: "x"}}
+
+	tests := []struct {
+		name  string
+		data  interface{}
+		err   error
+		json  bool
+		reply string
+	}{
+		{"message", Message("reloaded"), nil, false, "ok: reloaded\n"},
+		{"json message", Message("reloaded"), nil, true, `{"ok":"reloaded"}` + "\n"},
+		{"text", data, nil, false, "- class: kitty\n  windows:\n      - 0x1\n      - 0x2\n"},
+		{"json", data, nil, true, `[{"class":"kitty","windows":["0x1","0x2"]}]` + "\n"},
+		{"error", nil, NewError(CodeNotFound, "item not found: %s", "foot"), false, "error: item not found: foot [4]\n"},
+		{"json error", nil, NewError(CodeArgs, "empty request"), true, `{"error":"empty request","code":2}` + "\n"},
+		{"plain error", nil, errors.New("failed"), false, "error: failed [1]\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply := string(Reply(test.data, test.err, test.json))
+			if reply != test.reply {
+				t.Fatalf("got %q, want %q", reply, test.reply)
+			}
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "dock.sock")
+
+	go StartServer(socket, func(request string) ([]byte, error) {
+		if request == "fail" {
+			return nil, errors.New("failed")
+		}
+		return []byte("got " + request + "\n"), nil
+	})
+	waitSocket(t, socket)
+
+	tests := map[string]string{
+		"item list":  "got item list\n",
+		"j/item get": "got j/item get\n",
+		"fail":       "error: failed\n",
+	}
+
+	for request, want := range tests {
+		reply, err := Send(socket, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(reply) != want {
+			t.Errorf("%q: got %q, want %q", request, reply, want)
+		}
+	}
+}
+
+func waitSocket(t *testing.T, socket string) {
+	t.Helper()
+
+	// a probe connection would subscribe to the event stream, check the file
+	waitFor(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	})
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFormatTextNil(t *testing.T) {
+	var missing *struct{ Name string }
+	if text := FormatText(map[string]interface{}{"item": missing}); !strings.HasPrefix(text, "item: none") {
+		t.Fatalf("got %q", text)
+	}
+}
@@ -0,0 +1,124 @@
+package dockIPC
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// subscribers that fall this many events behind are disconnected
+const eventBuffer = 256
+
+type eventServer struct {
+	subscribers map[net.Conn]chan string
+	mu          sync.Mutex
+}
+
+var events = &eventServer{
+	subscribers: make(map[net.Conn]chan string),
+}
+
+// EventSocketPath returns the location of the dock event stream socket
+func EventSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("hypr-dock2-%s.sock", os.Getenv("USER")))
+	}
+
+	return filepath.Join(dir, "hypr-dock2.sock")
+}
+
+// StartEventServer streams every event sent with SendEvent to the connected clients
+// as "name>>data" lines. It blocks until the listener is closed.
+func StartEventServer(fileName string) error {
+	if err := os.RemoveAll(fileName); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("unix", fileName)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(fileName, 0600); err != nil {
+		return err
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			continue
+		}
+
+		events.subscribe(conn)
+	}
+}
+
+// SendEvent broadcasts "name>>data[,data...]" to the event stream subscribers
+func SendEvent(name string, data ...string) {
+	line := name + ">>" + strings.Join(data, ",") + "\n"
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	for conn, queue := range events.subscribers {
+		select {
+		case queue <- line:
+		default:
+			events.unsubscribeUnsafe(conn)
+		}
+	}
+}
+
+func (es *eventServer) subscribe(conn net.Conn) {
+	queue := make(chan string, eventBuffer)
+
+	es.mu.Lock()
+	es.subscribers[conn] = queue
+	es.mu.Unlock()
+
+	go func() {
+		for line := range queue {
+			if _, err := conn.Write([]byte(line)); err != nil {
+				es.unsubscribe(conn)
+				return
+			}
+		}
+	}()
+
+	// detect closed clients even when no events are sent
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				es.unsubscribe(conn)
+				return
+			}
+		}
+	}()
+}
+
+func (es *eventServer) unsubscribe(conn net.Conn) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.unsubscribeUnsafe(conn)
+}
+
+func (es *eventServer) unsubscribeUnsafe(conn net.Conn) {
+	queue, exist := es.subscribers[conn]
+	if !exist {
+		return
+	}
+
+	delete(es.subscribers, conn)
+	close(queue)
+	conn.Close()
+}
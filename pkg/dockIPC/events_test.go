@@ -0,0 +1,52 @@
+package dockIPC
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventServer(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "dock2.sock")
+
+	go StartEventServer(socket)
+	waitSocket(t, socket)
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the server registers the subscriber after Accept
+	waitFor(t, func() bool {
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		return len(events.subscribers) == 1
+	})
+
+	SendEvent("additem", "kitty")
+	SendEvent("moveitem", "kitty", "firefox")
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{"additem>>kitty\n", "moveitem>>kitty,firefox\n"} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != want {
+			t.Fatalf("got %q, want %q", line, want)
+		}
+	}
+
+	// a closed client is unsubscribed
+	conn.Close()
+	waitFor(t, func() bool {
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		return len(events.subscribers) == 0
+	})
+}
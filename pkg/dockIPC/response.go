@@ -0,0 +1,215 @@
+package dockIPC
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Exit codes carried by error replies
+const (
+	CodeOK = iota
+	CodeGeneral
+	CodeArgs
+	CodeAccess
+	CodeNotFound
+)
+
+type Error struct {
+	Message string
+	Code    int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func NewError(code int, format string, a ...interface{}) *Error {
+	return &Error{
+		Message: fmt.Sprintf(format, a...),
+		Code:    code,
+	}
+}
+
+// Message is a reply without data ("ok: <message>")
+type Message string
+
+// Reply renders the handler result as text or JSON
+func Reply(data interface{}, err error, isJSON bool) []byte {
+	if err != nil {
+		return replyError(err, isJSON)
+	}
+
+	if msg, ok := data.(Message); ok {
+		if isJSON {
+			out, _ := json.Marshal(map[string]string{"ok": string(msg)})
+			return append(out, '\n')
+		}
+		return []byte("ok: " + string(msg) + "\n")
+	}
+
+	if isJSON {
+		out, err := json.Marshal(data)
+		if err != nil {
+			return replyError(err, isJSON)
+		}
+		return append(out, '\n')
+	}
+
+	return []byte(FormatText(data))
+}
+
+func replyError(err error, isJSON bool) []byte {
+	code := CodeGeneral
+
+	var ipcErr *Error
+	if errors.As(err, &ipcErr) {
+		code = ipcErr.Code
+	}
+
+	if isJSON {
+		out, _ := json.Marshal(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{err.Error(), code})
+		return append(out, '\n')
+	}
+
+	return []byte(fmt.Sprintf("error: %s [%d]\n", err.Error(), code))
+}
+
+// FormatText renders structs, maps and slices as "key: value" lines,
+// nesting with 4 spaces and listing with "- "
+func FormatText(data interface{}) string {
+	var b strings.Builder
+	writeValue(&b, reflect.ValueOf(data), 0)
+	return b.String()
+}
+
+func writeValue(b *strings.Builder, v reflect.Value, indent int) {
+	v = deref(v)
+	if !v.IsValid() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, field := range structFields(v) {
+			writeField(b, field.name, field.value, indent)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			writeField(b, fmt.Sprint(key), v.MapIndex(key), indent)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeListItem(b, v.Index(i), indent)
+		}
+	default:
+		b.WriteString(pad(indent) + scalar(v) + "\n")
+	}
+}
+
+func writeField(b *strings.Builder, key string, v reflect.Value, indent int) {
+	v = deref(v)
+	if isComposite(v) {
+		b.WriteString(pad(indent) + key + ":\n")
+		writeValue(b, v, indent+4)
+		return
+	}
+
+	b.WriteString(pad(indent) + key + ": " + scalar(v) + "\n")
+}
+
+func writeListItem(b *strings.Builder, v reflect.Value, indent int) {
+	v = deref(v)
+	if !isComposite(v) {
+		b.WriteString(pad(indent) + "- " + scalar(v) + "\n")
+		return
+	}
+
+	var nested strings.Builder
+	writeValue(&nested, v, 0)
+
+	lines := strings.Split(strings.TrimRight(nested.String(), "\n"), "\n")
+	for i, line := range lines {
+		prefix := "  "
+		if i == 0 {
+			prefix = "- "
+		}
+		b.WriteString(pad(indent) + prefix + line + "\n")
+	}
+}
+
+type namedValue struct {
+	name  string
+	value reflect.Value
+}
+
+func structFields(v reflect.Value) []namedValue {
+	var fields []namedValue
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields = append(fields, namedValue{name, v.Field(i)})
+	}
+
+	return fields
+}
+
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isComposite(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func scalar(v reflect.Value) string {
+	if !v.IsValid() {
+		return "none"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func pad(indent int) string {
+	return strings.Repeat(" ", indent)
+}
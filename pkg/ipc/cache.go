@@ -0,0 +1,677 @@
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// delay of the resync that fills the fields missing in the events (pid, geometry...)
+var resyncDelay = 500 * time.Millisecond
+
+// cache mirrors the Hyprland state and keeps it current from the event socket,
+// so that hot paths do not need a hyprctl round-trip.
+// Snapshots and events are applied in the event queue, one at a time.
+type cache struct {
+	clients    []Client
+	workspaces []Workspace
+	monitors   []Monitor
+
+	layers      []Layer
+	layersValid bool
+	layersMu    sync.Mutex
+
+	// class of the last activewindow, it is followed by activewindowv2 with the address
+	activeClass string
+
+	started      bool
+	resyncTimer  *time.Timer
+	clientsTimer *time.Timer
+	mu           sync.RWMutex
+
+	listeners []*EventListener
+	// closed by ResetCache to end the periodic resync, nil until StartCache
+	stop    chan struct{}
+	startMu sync.Mutex
+}
+
+var hyprState = &cache{}
+
+// StartCache loads clients, workspaces, monitors and layers, keeps them current
+// from the events of InitHyprEvents and reloads them every interval
+func StartCache(interval time.Duration) error {
+	hyprState.startMu.Lock()
+	defer hyprState.startMu.Unlock()
+
+	if hyprState.stop != nil {
+		return nil
+	}
+
+	stop := make(chan struct{})
+	hyprState.stop = stop
+	hyprState.subscribe()
+	DetectVersion()
+
+	var err error
+	queue.runWait(func() {
+		err = hyprState.resync()
+	})
+
+	hyprState.mu.Lock()
+	hyprState.started = true
+	hyprState.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ResyncCache()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return err
+}
+
+// ResetCache stops the cache and drops its state, the next StartCache loads it again,
+// e.g. from another ipctest.Server. It must not be called from an event handler.
+func ResetCache() {
+	hyprState.startMu.Lock()
+	defer hyprState.startMu.Unlock()
+
+	if hyprState.stop == nil {
+		return
+	}
+	close(hyprState.stop)
+	hyprState.stop = nil
+
+	for _, listener := range hyprState.listeners {
+		listener.Remove()
+	}
+	hyprState.listeners = nil
+
+	// wait for the event being handled
+	queue.runWait(func() {
+		hyprState.mu.Lock()
+		defer hyprState.mu.Unlock()
+
+		if hyprState.resyncTimer != nil {
+			hyprState.resyncTimer.Stop()
+			hyprState.resyncTimer = nil
+		}
+		if hyprState.clientsTimer != nil {
+			hyprState.clientsTimer.Stop()
+			hyprState.clientsTimer = nil
+		}
+		hyprState.clients = nil
+		hyprState.workspaces = nil
+		hyprState.monitors = nil
+		hyprState.activeClass = ""
+		hyprState.started = false
+	})
+
+	hyprState.layersMu.Lock()
+	hyprState.layers = nil
+	hyprState.layersValid = false
+	hyprState.layersMu.Unlock()
+}
+
+func CacheStarted() bool {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+	return hyprState.started
+}
+
+// ResyncCache reloads the whole state from hyprctl.
+// It must not be called from an event handler.
+func ResyncCache() error {
+	var err error
+	queue.runWait(func() {
+		err = hyprState.resync()
+	})
+	if err != nil {
+		log.Printf("IPC: cache resync failed: %v", err)
+	}
+	return err
+}
+
+// RefreshClients reloads only the clients, e.g. to get the current geometry
+// that is not reported by the events. It must not be called from an event handler.
+func RefreshClients() ([]Client, error) {
+	var err error
+	queue.runWait(func() {
+		var clients []Client
+		clients, err = GetClients()
+		if err != nil {
+			return
+		}
+
+		hyprState.mu.Lock()
+		hyprState.setClients(clients)
+		hyprState.mu.Unlock()
+	})
+	if err != nil {
+		log.Printf("IPC: failed to reload clients: %v", err)
+		return nil, err
+	}
+
+	return CachedClients(), nil
+}
+
+// InvalidateLayers makes the next CachedLayers call reload j/layers.
+// Layer geometry has no events, call it when a layer surface is resized.
+func InvalidateLayers() {
+	hyprState.layersMu.Lock()
+	defer hyprState.layersMu.Unlock()
+	hyprState.layersValid = false
+}
+
+func CachedClients() []Client {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+	return append([]Client{}, hyprState.clients...)
+}
+
+func CachedClient(address string) (Client, bool) {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+
+	i := hyprState.clientIndex(address)
+	if i < 0 {
+		return Client{}, false
+	}
+	return hyprState.clients[i], true
+}
+
+// CachedActiveWindow returns the client that was focused last
+func CachedActiveWindow() (Client, bool) {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+
+	i := hyprState.activeIndex()
+	if i < 0 {
+		return Client{}, false
+	}
+	return hyprState.clients[i], true
+}
+
+// CachedWorkspaces returns the workspaces with the window count taken from the clients
+func CachedWorkspaces() []Workspace {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+
+	workspaces := append([]Workspace{}, hyprState.workspaces...)
+	for i := range workspaces {
+		workspaces[i].Windows = 0
+		for _, client := range hyprState.clients {
+			if client.Workspace.Id == workspaces[i].Id {
+				workspaces[i].Windows++
+			}
+		}
+	}
+	return workspaces
+}
+
+func CachedMonitors() []Monitor {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+	return append([]Monitor{}, hyprState.monitors...)
+}
+
+func CachedMonitor(name string) (Monitor, bool) {
+	hyprState.mu.RLock()
+	defer hyprState.mu.RUnlock()
+
+	for _, monitor := range hyprState.monitors {
+		if monitor.Name == name {
+			return monitor, true
+		}
+	}
+	return Monitor{}, false
+}
+
+// CachedLayers returns the layer surfaces, j/layers is only requested
+// after a layer was opened, closed or invalidated
+func CachedLayers() ([]Layer, error) {
+	hyprState.layersMu.Lock()
+	defer hyprState.layersMu.Unlock()
+
+	if !hyprState.layersValid {
+		layers, err := GetLayers()
+		if err != nil {
+			return nil, err
+		}
+		hyprState.layers = layers
+		hyprState.layersValid = true
+	}
+
+	return append([]Layer{}, hyprState.layers...), nil
+}
+
+func CachedLayer(namespace string) (Layer, error) {
+	layers, err := CachedLayers()
+	if err != nil {
+		return Layer{}, err
+	}
+
+	for _, layer := range layers {
+		if layer.Namespace == namespace {
+			return layer, nil
+		}
+	}
+	return Layer{}, fmt.Errorf("%s layer not found", namespace)
+}
+
+func (c *cache) resync() error {
+	clients, clientsErr := GetClients()
+	workspaces, workspacesErr := GetWorkspaces()
+	monitors, monitorsErr := GetMonitors()
+
+	c.mu.Lock()
+	if clientsErr == nil {
+		c.setClients(clients)
+	}
+	if workspacesErr == nil {
+		c.workspaces = workspaces
+	}
+	if monitorsErr == nil {
+		c.monitors = monitors
+	}
+	c.mu.Unlock()
+
+	InvalidateLayers()
+
+	return errors.Join(clientsErr, workspacesErr, monitorsErr)
+}
+
+// setClients replaces the clients under the lock. Without focusHistoryID in j/clients,
+// the focus order tracked from activewindowv2 is kept and new clients are put last.
+func (c *cache) setClients(clients []Client) {
+	if GetCapabilities().FocusHistoryID {
+		c.clients = clients
+		return
+	}
+
+	next := 0
+	history := make(map[string]int)
+	for _, client := range c.clients {
+		if client.FocusHistoryID >= 0 {
+			history[client.Address] = client.FocusHistoryID
+			next = max(next, client.FocusHistoryID+1)
+		}
+	}
+
+	for i := range clients {
+		id, exist := history[clients[i].Address]
+		if !exist {
+			id = next
+			next++
+		}
+		clients[i].FocusHistoryID = id
+	}
+
+	// close the gaps left by the closed windows
+	order := make([]int, len(clients))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return clients[order[a]].FocusHistoryID < clients[order[b]].FocusHistoryID
+	})
+	for id, i := range order {
+		clients[i].FocusHistoryID = id
+	}
+
+	c.clients = clients
+}
+
+// resyncLater debounces a resync after a burst of events
+func (c *cache) resyncLater() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resyncTimer != nil {
+		c.resyncTimer.Stop()
+	}
+	c.resyncTimer = time.AfterFunc(resyncDelay, func() {
+		if CacheStarted() {
+			ResyncCache()
+		}
+	})
+}
+
+// refreshClientsLater debounces a reload of the clients alone,
+// for the geometry that the window events don't carry
+func (c *cache) refreshClientsLater() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.clientsTimer != nil {
+		c.clientsTimer.Stop()
+	}
+	c.clientsTimer = time.AfterFunc(resyncDelay, func() {
+		if CacheStarted() {
+			RefreshClients()
+		}
+	})
+}
+
+func (c *cache) resyncMonitors() {
+	monitors, err := GetMonitors()
+	if err != nil {
+		log.Printf("IPC: failed to reload monitors: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	c.monitors = monitors
+	c.mu.Unlock()
+
+	InvalidateLayers()
+}
+
+func (c *cache) clientIndex(address string) int {
+	for i, client := range c.clients {
+		if client.Address == address {
+			return i
+		}
+	}
+	return -1
+}
+
+// activeIndex returns the client at the front of the focus history
+func (c *cache) activeIndex() int {
+	for i, client := range c.clients {
+		if client.FocusHistoryID == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *cache) workspaceIndex(id int) int {
+	for i, workspace := range c.workspaces {
+		if workspace.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *cache) monitorID(name string) int {
+	for _, monitor := range c.monitors {
+		if monitor.Name == name {
+			return monitor.Id
+		}
+	}
+	return -1
+}
+
+func (c *cache) focusedMonitor() int {
+	for i, monitor := range c.monitors {
+		if monitor.Focused {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateClient applies the change to the client with the address under the lock
+func (c *cache) updateClient(address string, change func(client *Client)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i := c.clientIndex(address); i >= 0 {
+		change(&c.clients[i])
+	}
+}
+
+func (c *cache) subscribe() {
+	c.listen(Subscribe(func(e OpenWindow) {
+		c.mu.Lock()
+		if i := c.clientIndex(e.Address); i >= 0 {
+			c.clients[i].Class = e.Class
+			c.clients[i].Title = e.Title
+		} else {
+			client := Client{
+				Address:        e.Address,
+				Mapped:         true,
+				At:             []int{0, 0},
+				Size:           []int{0, 0},
+				Class:          e.Class,
+				Title:          e.Title,
+				InitialClass:   e.Class,
+				InitialTitle:   e.Title,
+				Monitor:        -1,
+				FocusHistoryID: -1,
+			}
+
+			for _, workspace := range c.workspaces {
+				if workspace.Name == e.Workspace {
+					client.Workspace.Id = workspace.Id
+					client.Workspace.Name = workspace.Name
+					client.Monitor = c.monitorID(workspace.Monitor)
+				}
+			}
+
+			c.clients = append(c.clients, client)
+		}
+		c.mu.Unlock()
+
+		c.resyncLater()
+	}, true))
+
+	c.listen(Subscribe(func(e CloseWindow) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		i := c.clientIndex(e.Address)
+		if i < 0 {
+			return
+		}
+
+		closed := c.clients[i].FocusHistoryID
+		c.clients = append(c.clients[:i], c.clients[i+1:]...)
+
+		if closed < 0 {
+			return
+		}
+		for i := range c.clients {
+			if c.clients[i].FocusHistoryID > closed {
+				c.clients[i].FocusHistoryID--
+			}
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e WindowTitleV2) {
+		classless := false
+		c.updateClient(e.Address, func(client *Client) {
+			client.Title = e.Title
+			classless = client.Class == ""
+		})
+
+		// a window without a class may have got it, there is no event for that
+		if classless {
+			c.resyncLater()
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e MoveWindowV2) {
+		c.mu.Lock()
+		monitor := -1
+		if i := c.workspaceIndex(e.WorkspaceID); i >= 0 {
+			monitor = c.monitorID(c.workspaces[i].Monitor)
+		}
+		c.mu.Unlock()
+
+		c.updateClient(e.Address, func(client *Client) {
+			client.Workspace.Id = e.WorkspaceID
+			client.Workspace.Name = e.WorkspaceName
+			if monitor >= 0 {
+				client.Monitor = monitor
+			}
+		})
+
+		// the geometry is not in the event
+		c.refreshClientsLater()
+	}, true))
+
+	c.listen(Subscribe(func(e ChangeFloatingMode) {
+		c.updateClient(e.Address, func(client *Client) {
+			client.Floating = e.Floating
+		})
+		c.refreshClientsLater()
+	}, true))
+
+	c.listen(Subscribe(func(e Pin) {
+		c.updateClient(e.Address, func(client *Client) {
+			client.Pinned = e.Pinned
+		})
+	}, true))
+
+	// the event is about the active window and has no mode, the resync fetches it
+	c.listen(Subscribe(func(e Fullscreen) {
+		c.mu.Lock()
+		if i := c.activeIndex(); i >= 0 {
+			client := &c.clients[i]
+			if !e.Enabled {
+				client.Fullscreen = 0
+			} else if client.Fullscreen == 0 {
+				client.Fullscreen = 2
+			}
+		}
+		c.mu.Unlock()
+
+		c.resyncLater()
+	}, true))
+
+	c.listen(Subscribe(func(e ActiveWindow) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.activeClass = e.Class
+	}, true))
+
+	c.listen(Subscribe(func(e ActiveWindowV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		i := c.clientIndex(e.Address)
+		if i < 0 {
+			return
+		}
+
+		// some apps set or change the class after mapping
+		if c.activeClass != "" {
+			c.clients[i].Class = c.activeClass
+			c.activeClass = ""
+		}
+
+		// the focused window moves to the front of the focus history
+		prev := c.clients[i].FocusHistoryID
+		for j := range c.clients {
+			id := c.clients[j].FocusHistoryID
+			if id >= 0 && (prev < 0 || id < prev) {
+				c.clients[j].FocusHistoryID++
+			}
+		}
+		c.clients[i].FocusHistoryID = 0
+	}, true))
+
+	c.listen(Subscribe(func(e CreateWorkspaceV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.workspaceIndex(e.ID) >= 0 {
+			return
+		}
+
+		workspace := Workspace{Id: e.ID, Name: e.Name}
+		if i := c.focusedMonitor(); i >= 0 {
+			workspace.Monitor = c.monitors[i].Name
+		}
+		c.workspaces = append(c.workspaces, workspace)
+	}, true))
+
+	c.listen(Subscribe(func(e DestroyWorkspaceV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if i := c.workspaceIndex(e.ID); i >= 0 {
+			c.workspaces = append(c.workspaces[:i], c.workspaces[i+1:]...)
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e WorkspaceV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if i := c.focusedMonitor(); i >= 0 {
+			c.monitors[i].ActiveWorkspace.Id = e.ID
+			c.monitors[i].ActiveWorkspace.Name = e.Name
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e MoveWorkspaceV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if i := c.workspaceIndex(e.ID); i >= 0 {
+			c.workspaces[i].Monitor = e.Monitor
+		}
+
+		monitor := c.monitorID(e.Monitor)
+		for i := range c.clients {
+			if c.clients[i].Workspace.Id == e.ID {
+				c.clients[i].Monitor = monitor
+			}
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e FocusedMonV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for i := range c.monitors {
+			c.monitors[i].Focused = c.monitors[i].Name == e.Monitor
+			if !c.monitors[i].Focused {
+				continue
+			}
+
+			c.monitors[i].ActiveWorkspace.Id = e.WorkspaceID
+			if j := c.workspaceIndex(e.WorkspaceID); j >= 0 {
+				c.monitors[i].ActiveWorkspace.Name = c.workspaces[j].Name
+			}
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e ActiveSpecialV2) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		for i := range c.monitors {
+			if c.monitors[i].Name == e.Monitor {
+				c.monitors[i].SpecialWorkspace.Id = e.ID
+				c.monitors[i].SpecialWorkspace.Name = e.Name
+			}
+		}
+	}, true))
+
+	c.listen(Subscribe(func(e MonitorAdded) { c.resyncMonitors() }, true))
+	c.listen(Subscribe(func(e MonitorRemoved) { c.resyncMonitors() }, true))
+	c.listen(Subscribe(func(e ConfigReloaded) { c.resyncMonitors() }, true))
+	c.listen(Subscribe(func(e OpenLayer) { InvalidateLayers() }, true))
+	c.listen(Subscribe(func(e CloseLayer) { InvalidateLayers() }, true))
+}
+
+// listen keeps the listener, so that ResetCache can remove it
+func (c *cache) listen(listener *EventListener) {
+	c.listeners = append(c.listeners, listener)
+}
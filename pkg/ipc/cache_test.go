@@ -0,0 +1,104 @@
+package ipc_test
+
+import (
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+)
+
+func TestCacheLoadsFixtures(t *testing.T) {
+	startCache(t)
+
+	if !ipc.CacheStarted() {
+		t.Fatal("cache not started")
+	}
+	if clients := ipc.CachedClients(); len(clients) != 2 {
+		t.Fatalf("got %d clients, want 2", len(clients))
+	}
+	if _, exist := ipc.CachedMonitor("DP-1"); !exist {
+		t.Fatal("DP-1 is not in the cache")
+	}
+
+	active, exist := ipc.CachedActiveWindow()
+	if !exist || active.Address != kitty {
+		t.Fatalf("active window %q, want %q", active.Address, kitty)
+	}
+}
+
+func TestCacheFollowsWindowEvents(t *testing.T) {
+	startCache(t)
+
+	sendEvent(t, "openwindow", "5a1b2c3d5070", "2", "foot", "~")
+	client := cachedClient(t, foot)
+	if client.Class != "foot" || client.Workspace.Id != 2 || client.Monitor != 0 {
+		t.Fatalf("unexpected client after openwindow %+v", client)
+	}
+
+	sendEvent(t, "windowtitlev2", "5a1b2c3d5070", "vim, main.go")
+	if title := cachedClient(t, foot).Title; title != "vim, main.go" {
+		t.Fatalf("title %q after windowtitlev2", title)
+	}
+
+	sendEvent(t, "movewindowv2", "5a1b2c3d5070", "1", "1")
+	if id := cachedClient(t, foot).Workspace.Id; id != 1 {
+		t.Fatalf("workspace %d after movewindowv2, want 1", id)
+	}
+
+	sendEvent(t, "changefloatingmode", "5a1b2c3d5070", "1")
+	if !cachedClient(t, foot).Floating {
+		t.Fatal("not floating after changefloatingmode")
+	}
+
+	sendEvent(t, "activewindowv2", "5a1b2c3d5070")
+	if id := cachedClient(t, foot).FocusHistoryID; id != 0 {
+		t.Fatalf("focus history %d of the active window", id)
+	}
+	if id := cachedClient(t, kitty).FocusHistoryID; id != 1 {
+		t.Fatalf("focus history %d of the previous window, want 1", id)
+	}
+
+	sendEvent(t, "closewindow", "5a1b2c3d5070")
+	if _, exist := ipc.CachedClient(foot); exist {
+		t.Fatal("closed window is still in the cache")
+	}
+	if id := cachedClient(t, kitty).FocusHistoryID; id != 0 {
+		t.Fatalf("focus history %d after the active window closed, want 0", id)
+	}
+	if id := cachedClient(t, firefox).FocusHistoryID; id != 1 {
+		t.Fatalf("focus history %d of firefox, want 1", id)
+	}
+}
+
+func TestCacheRefreshesGeometry(t *testing.T) {
+	restoreFixtures(t)
+	startCache(t)
+	ipc.SetResyncDelay(10 * time.Millisecond)
+
+	clients, err := ipc.GetClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients[1].At = []int{100, 100}
+	if err := srv.SetClients(clients); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := len(srv.Requests())
+	sendEvent(t, "movewindowv2", "5a1b2c3d4f60", "1", "1")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cachedClient(t, firefox).At[0] != 100 {
+		if time.Now().After(deadline) {
+			t.Fatal("geometry not refreshed after movewindowv2")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// only the clients are reloaded, a full resync would read the workspaces too
+	for _, request := range srv.Requests()[sent:] {
+		if request == "j/workspaces" {
+			t.Fatal("full resync after movewindowv2")
+		}
+	}
+}
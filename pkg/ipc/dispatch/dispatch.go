@@ -0,0 +1,149 @@
+// Package dispatch builds Hyprland dispatchers and runs them alone or
+// as a single [[BATCH]] request
+package dispatch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"hypr-dock/pkg/ipc"
+)
+
+// Command is a dispatcher with its arguments, e.g. "focuswindow address:0x..."
+type Command struct {
+	Dispatcher string
+	Args       string
+}
+
+func (c Command) String() string {
+	return strings.TrimSpace("dispatch " + c.Dispatcher + " " + c.Args)
+}
+
+func window(address string) string {
+	return "address:" + address
+}
+
+func FocusWindow(address string) Command {
+	return Command{"focuswindow", window(address)}
+}
+
+func CloseWindow(address string) Command {
+	return Command{"closewindow", window(address)}
+}
+
+// MoveToWorkspace moves the window and follows it
+func MoveToWorkspace(workspace string, address string) Command {
+	return Command{"movetoworkspace", workspace + "," + window(address)}
+}
+
+// MoveToWorkspaceSilent moves the window without changing the active workspace
+func MoveToWorkspaceSilent(workspace string, address string) Command {
+	return Command{"movetoworkspacesilent", workspace + "," + window(address)}
+}
+
+func ToggleFloating(address string) Command {
+	return Command{"togglefloating", window(address)}
+}
+
+// Fullscreen toggles the active window: 0 fullscreen, 1 maximize
+func Fullscreen(mode int) Command {
+	return Command{"fullscreen", strconv.Itoa(mode)}
+}
+
+func Pin(address string) Command {
+	return Command{"pin", window(address)}
+}
+
+func Workspace(workspace string) Command {
+	return Command{"workspace", workspace}
+}
+
+// ToggleSpecialWorkspace shows or hides special:name, an empty name is special:special
+func ToggleSpecialWorkspace(name string) Command {
+	if name == "special" {
+		name = ""
+	}
+	return Command{"togglespecialworkspace", name}
+}
+
+func Exec(command string) Command {
+	return Command{"exec", command}
+}
+
+// Run sends one dispatcher, a rejected one returns *ipc.ReplyError
+func Run(cmd Command) error {
+	_, err := ipc.Hyprctl(cmd.String())
+	return err
+}
+
+// Batch sends the commands in one [[BATCH]] request, so Hyprland applies them
+// together. The first error is about the request itself, the slice holds
+// the result of every command in order. Hyprland releases differ in how they
+// separate the replies, a failed command whose reply can't be told apart
+// makes the whole batch fail.
+func Batch(cmds ...Command) ([]error, error) {
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+
+	parts := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		// ";" separates the batch commands
+		if strings.Contains(cmd.String(), ";") {
+			return nil, fmt.Errorf("batch command %q contains \";\"", cmd.String())
+		}
+		parts[i] = cmd.String()
+	}
+
+	request := "[[BATCH]]" + strings.Join(parts, ";")
+	response, err := ipc.Hyprctl(request)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]error, len(cmds))
+	reply := strings.TrimSpace(string(response))
+	if reply == "" {
+		return nil, fmt.Errorf("%w: %q: empty reply", ipc.ErrPartialResponse, request)
+	}
+	if onlyOK(reply) {
+		return results, nil
+	}
+
+	replies := strings.Split(reply, "\n\n")
+	if len(replies) != len(cmds) {
+		return nil, &ipc.ReplyError{Request: request, Reply: reply}
+	}
+
+	for i, reply := range replies {
+		reply = strings.TrimSpace(reply)
+		if reply != "ok" {
+			results[i] = &ipc.ReplyError{Request: parts[i], Reply: reply}
+		}
+	}
+
+	return results, nil
+}
+
+// onlyOK tells if the reply is "ok" for every command, with or without separators
+func onlyOK(reply string) bool {
+	for reply != "" {
+		rest, ok := strings.CutPrefix(reply, "ok")
+		if !ok {
+			return false
+		}
+		reply = strings.TrimSpace(rest)
+	}
+	return true
+}
+
+// BatchErr is Batch with all errors joined
+func BatchErr(cmds ...Command) error {
+	results, err := Batch(cmds...)
+	if err != nil {
+		return err
+	}
+	return errors.Join(results...)
+}
@@ -0,0 +1,132 @@
+package dispatch_test
+
+import (
+	"errors"
+	"testing"
+
+	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/dispatch"
+	"hypr-dock/pkg/ipc/ipctest"
+)
+
+func newServer(t *testing.T, separator string) *ipctest.Server {
+	t.Helper()
+
+	srv, err := ipctest.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	srv.BatchSeparator = separator
+	return srv
+}
+
+// separators of the batch replies in the Hyprland releases
+var separators = map[string]string{
+	"none":         "",
+	"blank lines":  "\n\n",
+	"single lines": "\n",
+}
+
+func TestBatch(t *testing.T) {
+	for name, separator := range separators {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(t, separator)
+
+			results, err := dispatch.Batch(
+				dispatch.FocusWindow("0x5a1b2c3d4e50"),
+				dispatch.FocusWindow("0x5a1b2c3d4f60"),
+				dispatch.FocusWindow("0x5a1b2c3d5070"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 3 {
+				t.Fatalf("got %d results for 3 commands", len(results))
+			}
+			for i, result := range results {
+				if result != nil {
+					t.Errorf("command %d: %v", i, result)
+				}
+			}
+
+			requests := srv.Requests()
+			want := "[[BATCH]]dispatch focuswindow address:0x5a1b2c3d4e50;" +
+				"dispatch focuswindow address:0x5a1b2c3d4f60;" +
+				"dispatch focuswindow address:0x5a1b2c3d5070"
+			if len(requests) != 1 || requests[0] != want {
+				t.Fatalf("requests %q, want %q", requests, want)
+			}
+		})
+	}
+}
+
+func TestBatchFailedCommand(t *testing.T) {
+	failed := dispatch.FocusWindow("0x5a1b2c3d4f60")
+
+	for name, separator := range separators {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(t, separator)
+			srv.SetReply(failed.String(), []byte("No such window found"))
+
+			results, err := dispatch.Batch(
+				dispatch.FocusWindow("0x5a1b2c3d4e50"),
+				failed,
+			)
+
+			var replyErr *ipc.ReplyError
+			if err == nil {
+				// the replies can be told apart, the error is on the failed command
+				if results[0] != nil {
+					t.Errorf("command 0: %v", results[0])
+				}
+				if !errors.As(results[1], &replyErr) || replyErr.Request != failed.String() {
+					t.Fatalf("command 1: got %v, want ReplyError", results[1])
+				}
+				return
+			}
+
+			if !errors.As(err, &replyErr) {
+				t.Fatalf("got %v, want ReplyError", err)
+			}
+		})
+	}
+}
+
+func TestBatchErr(t *testing.T) {
+	srv := newServer(t, "\n\n")
+	srv.SetReply("dispatch workspace 7", []byte("Invalid workspace"))
+
+	err := dispatch.BatchErr(
+		dispatch.Command{Dispatcher: "workspace", Args: "1"},
+		dispatch.Command{Dispatcher: "workspace", Args: "7"},
+	)
+
+	var replyErr *ipc.ReplyError
+	if !errors.As(err, &replyErr) || replyErr.Reply != "Invalid workspace" {
+		t.Fatalf("got %v, want the reply of the failed command", err)
+	}
+}
+
+func TestBatchRejectsSeparator(t *testing.T) {
+	srv := newServer(t, "")
+
+	_, err := dispatch.Batch(dispatch.Command{Dispatcher: "exec", Args: "a; b"})
+	if err == nil {
+		t.Fatal("command with \";\" was accepted")
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Fatalf("requests %q sent", requests)
+	}
+}
+
+func TestBatchSocketMissing(t *testing.T) {
+	ipc.SetSocketDir(t.TempDir())
+	defer ipc.SetSocketDir("")
+
+	_, err := dispatch.Batch(dispatch.FocusWindow("0x5a1b2c3d4e50"))
+	if !errors.Is(err, ipc.ErrSocketMissing) {
+		t.Fatalf("got %v, want ErrSocketMissing", err)
+	}
+}
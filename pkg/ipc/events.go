@@ -0,0 +1,322 @@
+package ipc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Event is a parsed line of the Hyprland event socket (.socket2.sock).
+// Window addresses are returned with the "0x" prefix, as in j/clients.
+type Event interface {
+	EventName() string
+}
+
+// RawEvent is returned for events without a typed model
+type RawEvent struct {
+	Name string
+	Data string
+}
+
+type OpenWindow struct {
+	Address   string
+	Workspace string
+	Class     string
+	Title     string
+}
+
+type CloseWindow struct {
+	Address string
+}
+
+type ActiveWindow struct {
+	Class string
+	Title string
+}
+
+// ActiveWindowV2 has an empty Address when no window is focused
+type ActiveWindowV2 struct {
+	Address string
+}
+
+type WindowTitle struct {
+	Address string
+}
+
+type WindowTitleV2 struct {
+	Address string
+	Title   string
+}
+
+type MoveWindow struct {
+	Address   string
+	Workspace string
+}
+
+type MoveWindowV2 struct {
+	Address       string
+	WorkspaceID   int
+	WorkspaceName string
+}
+
+type WorkspaceV2 struct {
+	ID   int
+	Name string
+}
+
+type CreateWorkspaceV2 struct {
+	ID   int
+	Name string
+}
+
+type DestroyWorkspaceV2 struct {
+	ID   int
+	Name string
+}
+
+type MoveWorkspaceV2 struct {
+	ID      int
+	Name    string
+	Monitor string
+}
+
+type FocusedMon struct {
+	Monitor   string
+	Workspace string
+}
+
+type FocusedMonV2 struct {
+	Monitor     string
+	WorkspaceID int
+}
+
+type MonitorAdded struct {
+	Name string
+}
+
+type MonitorAddedV2 struct {
+	ID          int
+	Name        string
+	Description string
+}
+
+type MonitorRemoved struct {
+	Name string
+}
+
+type MonitorRemovedV2 struct {
+	ID          int
+	Name        string
+	Description string
+}
+
+// ActiveSpecial has an empty Workspace when the special workspace is closed
+type ActiveSpecial struct {
+	Workspace string
+	Monitor   string
+}
+
+type ActiveSpecialV2 struct {
+	ID      int
+	Name    string
+	Monitor string
+}
+
+type ChangeFloatingMode struct {
+	Address  string
+	Floating bool
+}
+
+type Fullscreen struct {
+	Enabled bool
+}
+
+type Urgent struct {
+	Address string
+}
+
+type Pin struct {
+	Address string
+	Pinned  bool
+}
+
+type OpenLayer struct {
+	Namespace string
+}
+
+type CloseLayer struct {
+	Namespace string
+}
+
+type ConfigReloaded struct{}
+
+func (e RawEvent) EventName() string         { return e.Name }
+func (OpenWindow) EventName() string         { return "openwindow" }
+func (CloseWindow) EventName() string        { return "closewindow" }
+func (ActiveWindow) EventName() string       { return "activewindow" }
+func (ActiveWindowV2) EventName() string     { return "activewindowv2" }
+func (WindowTitle) EventName() string        { return "windowtitle" }
+func (WindowTitleV2) EventName() string      { return "windowtitlev2" }
+func (MoveWindow) EventName() string         { return "movewindow" }
+func (MoveWindowV2) EventName() string       { return "movewindowv2" }
+func (WorkspaceV2) EventName() string        { return "workspacev2" }
+func (CreateWorkspaceV2) EventName() string  { return "createworkspacev2" }
+func (DestroyWorkspaceV2) EventName() string { return "destroyworkspacev2" }
+func (MoveWorkspaceV2) EventName() string    { return "moveworkspacev2" }
+func (FocusedMon) EventName() string         { return "focusedmon" }
+func (FocusedMonV2) EventName() string       { return "focusedmonv2" }
+func (MonitorAdded) EventName() string       { return "monitoradded" }
+func (MonitorAddedV2) EventName() string     { return "monitoraddedv2" }
+func (MonitorRemoved) EventName() string     { return "monitorremoved" }
+func (MonitorRemovedV2) EventName() string   { return "monitorremovedv2" }
+func (ActiveSpecial) EventName() string      { return "activespecial" }
+func (ActiveSpecialV2) EventName() string    { return "activespecialv2" }
+func (ChangeFloatingMode) EventName() string { return "changefloatingmode" }
+func (Fullscreen) EventName() string         { return "fullscreen" }
+func (Urgent) EventName() string             { return "urgent" }
+func (Pin) EventName() string                { return "pin" }
+func (OpenLayer) EventName() string          { return "openlayer" }
+func (CloseLayer) EventName() string         { return "closelayer" }
+func (ConfigReloaded) EventName() string     { return "configreloaded" }
+
+type eventParser struct {
+	fields int
+	parse  func(f []string) (Event, error)
+}
+
+var eventParsers = map[string]eventParser{
+	"openwindow": {4, func(f []string) (Event, error) {
+		return OpenWindow{Address: address(f[0]), Workspace: f[1], Class: f[2], Title: f[3]}, nil
+	}},
+	"closewindow": {1, func(f []string) (Event, error) {
+		return CloseWindow{Address: address(f[0])}, nil
+	}},
+	"activewindow": {2, func(f []string) (Event, error) {
+		return ActiveWindow{Class: f[0], Title: f[1]}, nil
+	}},
+	"activewindowv2": {1, func(f []string) (Event, error) {
+		return ActiveWindowV2{Address: address(f[0])}, nil
+	}},
+	"windowtitle": {1, func(f []string) (Event, error) {
+		return WindowTitle{Address: address(f[0])}, nil
+	}},
+	"windowtitlev2": {2, func(f []string) (Event, error) {
+		return WindowTitleV2{Address: address(f[0]), Title: f[1]}, nil
+	}},
+	"movewindow": {2, func(f []string) (Event, error) {
+		return MoveWindow{Address: address(f[0]), Workspace: f[1]}, nil
+	}},
+	"movewindowv2": {3, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[1])
+		return MoveWindowV2{Address: address(f[0]), WorkspaceID: id, WorkspaceName: f[2]}, err
+	}},
+	"workspacev2": {2, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return WorkspaceV2{ID: id, Name: f[1]}, err
+	}},
+	"createworkspacev2": {2, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return CreateWorkspaceV2{ID: id, Name: f[1]}, err
+	}},
+	"destroyworkspacev2": {2, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return DestroyWorkspaceV2{ID: id, Name: f[1]}, err
+	}},
+	"moveworkspacev2": {3, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return MoveWorkspaceV2{ID: id, Name: f[1], Monitor: f[2]}, err
+	}},
+	"focusedmon": {2, func(f []string) (Event, error) {
+		return FocusedMon{Monitor: f[0], Workspace: f[1]}, nil
+	}},
+	"focusedmonv2": {2, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[1])
+		return FocusedMonV2{Monitor: f[0], WorkspaceID: id}, err
+	}},
+	"monitoradded": {1, func(f []string) (Event, error) {
+		return MonitorAdded{Name: f[0]}, nil
+	}},
+	"monitoraddedv2": {3, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return MonitorAddedV2{ID: id, Name: f[1], Description: f[2]}, err
+	}},
+	"monitorremoved": {1, func(f []string) (Event, error) {
+		return MonitorRemoved{Name: f[0]}, nil
+	}},
+	"monitorremovedv2": {3, func(f []string) (Event, error) {
+		id, err := strconv.Atoi(f[0])
+		return MonitorRemovedV2{ID: id, Name: f[1], Description: f[2]}, err
+	}},
+	"activespecial": {2, func(f []string) (Event, error) {
+		return ActiveSpecial{Workspace: f[0], Monitor: f[1]}, nil
+	}},
+	"activespecialv2": {3, func(f []string) (Event, error) {
+		// the id is empty when the special workspace is closed
+		id := 0
+		var err error
+		if f[0] != "" {
+			id, err = strconv.Atoi(f[0])
+		}
+		return ActiveSpecialV2{ID: id, Name: f[1], Monitor: f[2]}, err
+	}},
+	"changefloatingmode": {2, func(f []string) (Event, error) {
+		return ChangeFloatingMode{Address: address(f[0]), Floating: f[1] == "1"}, nil
+	}},
+	"fullscreen": {1, func(f []string) (Event, error) {
+		return Fullscreen{Enabled: f[0] == "1"}, nil
+	}},
+	"urgent": {1, func(f []string) (Event, error) {
+		return Urgent{Address: address(f[0])}, nil
+	}},
+	"pin": {2, func(f []string) (Event, error) {
+		return Pin{Address: address(f[0]), Pinned: f[1] == "1"}, nil
+	}},
+	"openlayer": {1, func(f []string) (Event, error) {
+		return OpenLayer{Namespace: f[0]}, nil
+	}},
+	"closelayer": {1, func(f []string) (Event, error) {
+		return CloseLayer{Namespace: f[0]}, nil
+	}},
+	"configreloaded": {0, func(f []string) (Event, error) {
+		return ConfigReloaded{}, nil
+	}},
+}
+
+// ParseEvent turns a "name>>data" line into a typed event.
+// Unknown events are returned as RawEvent.
+func ParseEvent(line string) (Event, error) {
+	name, data, _ := strings.Cut(strings.TrimSpace(line), ">>")
+
+	parser, exist := eventParsers[name]
+	if !exist {
+		return RawEvent{Name: name, Data: data}, nil
+	}
+
+	fields := []string{}
+	if parser.fields > 0 {
+		fields = strings.SplitN(data, ",", parser.fields)
+	}
+
+	// e.g. "activewindow>>" is sent without the separator when nothing is focused
+	for len(fields) < parser.fields {
+		fields = append(fields, "")
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	event, err := parser.parse(fields)
+	if err != nil {
+		return nil, fmt.Errorf("event %q: %w", name, err)
+	}
+
+	return event, nil
+}
+
+func address(addr string) string {
+	if addr == "" || strings.HasPrefix(addr, "0x") {
+		return addr
+	}
+	return "0x" + addr
+}
@@ -0,0 +1,76 @@
+package ipc_test
+
+import (
+	"testing"
+
+	"hypr-dock/pkg/ipc"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		line  string
+		event ipc.Event
+	}{
+		{"openwindow>>5a1b2c3d5070,2,foot,vim, main.go\n", ipc.OpenWindow{Address: foot, Workspace: "2", Class: "foot", Title: "vim, main.go"}},
+		{"closewindow>>5a1b2c3d5070", ipc.CloseWindow{Address: foot}},
+		{"activewindow>>", ipc.ActiveWindow{}},
+		{"activewindowv2>>", ipc.ActiveWindowV2{}},
+		{"windowtitlev2>>5a1b2c3d4e50,a, b", ipc.WindowTitleV2{Address: kitty, Title: "a, b"}},
+		{"movewindowv2>>5a1b2c3d4e50,3,3", ipc.MoveWindowV2{Address: kitty, WorkspaceID: 3, WorkspaceName: "3"}},
+		{"activespecialv2>>,,DP-1", ipc.ActiveSpecialV2{Monitor: "DP-1"}},
+		{"activespecialv2>>-98,special:term,DP-1", ipc.ActiveSpecialV2{ID: -98, Name: "special:term", Monitor: "DP-1"}},
+		{"changefloatingmode>>5a1b2c3d4e50,1", ipc.ChangeFloatingMode{Address: kitty, Floating: true}},
+		{"configreloaded>>", ipc.ConfigReloaded{}},
+		{"submap>>resize", ipc.RawEvent{Name: "submap", Data: "resize"}},
+	}
+
+	for _, test := range tests {
+		event, err := ipc.ParseEvent(test.line)
+		if err != nil {
+			t.Errorf("%q: %v", test.line, err)
+			continue
+		}
+		if event != test.event {
+			t.Errorf("%q: got %#v, want %#v", test.line, event, test.event)
+		}
+	}
+}
+
+func TestParseEventInvalid(t *testing.T) {
+	tests := []string{
+		"movewindowv2>>5a1b2c3d4e50,three,3",
+		"workspacev2>>,",
+		"monitoraddedv2>>x,DP-2,",
+	}
+
+	for _, line := range tests {
+		if event, err := ipc.ParseEvent(line); err == nil {
+			t.Errorf("%q: got %#v, want an error", line, event)
+		}
+	}
+}
+
+func TestSubscribeExactName(t *testing.T) {
+	var titles []ipc.WindowTitle
+	listener := ipc.Subscribe(func(e ipc.WindowTitle) {
+		titles = append(titles, e)
+	}, true)
+	defer listener.Remove()
+
+	var lines []string
+	raw := ipc.AddEventListener("windowtitlev2", func(line string) {
+		lines = append(lines, line)
+	}, true)
+	defer raw.Remove()
+
+	// the marker of sendEvent orders the reads after the handlers
+	sendEvent(t, "windowtitlev2", "5a1b2c3d4e50", "htop")
+	sendEvent(t, "windowtitle", "5a1b2c3d4e50")
+
+	if len(titles) != 1 || titles[0].Address != kitty {
+		t.Fatalf("windowtitle listener got %+v", titles)
+	}
+	if len(lines) != 1 || lines[0] != "windowtitlev2>>5a1b2c3d4e50,htop" {
+		t.Fatalf("windowtitlev2 listener got %q", lines)
+	}
+}
@@ -0,0 +1,11 @@
+package ipc
+
+import "time"
+
+// SetResyncDelay lets the tests see the cache as the events left it,
+// before the resync after an event reloads it from the fixtures
+func SetResyncDelay(delay time.Duration) {
+	hyprState.mu.Lock()
+	defer hyprState.mu.Unlock()
+	resyncDelay = delay
+}
@@ -3,55 +3,65 @@ package ipc
 import (
 	"fmt"
 	"log"
-
-	"github.com/goccy/go-json"
 )
 
 func GetMonitors() ([]Monitor, error) {
 	var monitors []Monitor
-	response, err := Hyprctl("j/monitors")
+	err := hyprctlJSON("j/monitors", &monitors)
+	return monitors, err
+}
+
+func GetWorkspaces() ([]Workspace, error) {
+	var workspaces []Workspace
+	err := hyprctlJSON("j/workspaces", &workspaces)
+	return workspaces, err
+}
+
+// GetLayers returns the layer surfaces of all monitors
+func GetLayers() ([]Layer, error) {
+	var monitors map[string]struct {
+		Levels map[string][]Layer `json:"levels"`
+	}
+	err := hyprctlJSON("j/layers", &monitors)
 	if err != nil {
 		return nil, err
 	}
-	err = json.Unmarshal([]byte(response), &monitors)
-	return monitors, err
+
+	var layers []Layer
+	for monitorName, monitor := range monitors {
+		for level, levelLayers := range monitor.Levels {
+			for _, layer := range levelLayers {
+				layer.Monitor = monitorName
+				layer.Level = level
+				layers = append(layers, layer)
+			}
+		}
+	}
+
+	return layers, nil
 }
 
 func GetClients() ([]Client, error) {
 	var clients []Client
-	response, err := Hyprctl("j/clients")
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal([]byte(response), &clients)
+	err := hyprctlJSON("j/clients", &clients)
 	return clients, err
 }
 
 func GetActiveWindow() (*Client, error) {
 	var activeWindow Client
-	response, err := Hyprctl("j/activewindow")
+	err := hyprctlJSON("j/activewindow", &activeWindow)
 	if err != nil {
 		log.Printf("Failed to get active window: %v", err)
 		return nil, err
 	}
-	err = json.Unmarshal([]byte(response), &activeWindow)
-	if err != nil {
-		log.Printf("Failed to unmarshal active window: %v", err)
-		return nil, err
-	}
 	return &activeWindow, nil
 }
 
 func GetOption(option string, v interface{}) error {
 	cmd := fmt.Sprintf("j/getoption %s", option)
-	response, err := Hyprctl(cmd)
-	if err != nil {
-		log.Printf("Failed to execute Hyprctl command for option '%s': %v", option, err)
-		return err
-	}
-	err = json.Unmarshal(response, v)
+	err := hyprctlJSON(cmd, v)
 	if err != nil {
-		log.Printf("Failed to unmarshal JSON response for option '%s': %v", option, err)
+		log.Printf("Failed to get option '%s': %v", option, err)
 		return err
 	}
 	return nil
@@ -1,35 +1,155 @@
 package ipc
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// DefaultTimeout limits a Hyprctl request without its own context deadline
+const DefaultTimeout = 2 * time.Second
+
+var (
+	// ErrSocketMissing is returned when Hyprland is not running
+	// or HYPRLAND_INSTANCE_SIGNATURE points to another instance
+	ErrSocketMissing = errors.New("hyprland socket not found")
+
+	// ErrPartialResponse is returned when the connection breaks or times out
+	// before the whole reply was read, or a JSON reply is incomplete
+	ErrPartialResponse = errors.New("partial hyprland response")
 )
 
+// ReplyError is a Hyprland error reply, e.g. "unknown request" or "Invalid dispatcher"
+type ReplyError struct {
+	Request string
+	Reply   string
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("hyprland: %q: %s", e.Request, e.Reply)
+}
+
 func Hyprctl(cmd string) (response []byte, err error) {
-	conn, err := net.Dial("unix", getUnixSockAdress())
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	return HyprctlContext(ctx, cmd)
+}
+
+// HyprctlContext sends the request to .socket.sock and reads the reply to EOF
+func HyprctlContext(ctx context.Context, cmd string) ([]byte, error) {
+	path := getUnixSockAdress()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
 	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, fmt.Errorf("%w: %s", ErrSocketMissing, path)
+		}
 		return nil, err
 	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// unblock the read when the context is canceled
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
 
-	message := []byte(cmd)
-	_, err = conn.Write(message)
+	_, err = conn.Write([]byte(cmd))
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
 
-	response = make([]byte, 102400)
-	n, err := conn.Read(response)
+	response, err := io.ReadAll(conn)
 	if err != nil {
-		return nil, err
+		return response, fmt.Errorf("%w: %q after %d bytes: %v", ErrPartialResponse, cmd, len(response), err)
 	}
 
-	defer conn.Close()
+	return response, checkReply(cmd, response)
+}
+
+func checkReply(cmd string, response []byte) error {
+	reply := strings.TrimSpace(string(response))
+
+	if reply == "unknown request" {
+		return &ReplyError{Request: cmd, Reply: reply}
+	}
+
+	if strings.HasPrefix(cmd, "j/") {
+		if reply == "" {
+			return fmt.Errorf("%w: %q: empty reply", ErrPartialResponse, cmd)
+		}
+		if reply[0] != '{' && reply[0] != '[' {
+			return &ReplyError{Request: cmd, Reply: reply}
+		}
+		if !json.Valid(response) {
+			return fmt.Errorf("%w: %q: invalid JSON of %d bytes", ErrPartialResponse, cmd, len(response))
+		}
+	}
+
+	if strings.HasPrefix(cmd, "dispatch ") && reply != "ok" {
+		return &ReplyError{Request: cmd, Reply: reply}
+	}
+
+	return nil
+}
+
+// hyprctlJSON sends a j/ request and decodes the reply into v
+func hyprctlJSON(cmd string, v interface{}) error {
+	response, err := Hyprctl(cmd)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(response, v)
+}
+
+var (
+	reconnectHandlers []func()
+	reconnectMu       sync.Mutex
+)
+
+// OnReconnect adds a handler called after .socket2.sock is connected again
+// or events were dropped from the full queue, once the cache is resynced.
+// The events in between are lost
+func OnReconnect(handler func()) {
+	reconnectMu.Lock()
+	defer reconnectMu.Unlock()
+	reconnectHandlers = append(reconnectHandlers, handler)
+}
 
-	return response[:n], nil
+func runReconnectHandlers() {
+	reconnectMu.Lock()
+	handlers := append([]func(){}, reconnectHandlers...)
+	reconnectMu.Unlock()
+
+	for _, handler := range handlers {
+		handler()
+	}
 }
 
 func InitHyprEvents() {
+	queue.start()
+	hyprVersion.fallbacks.Do(subscribeFallbacks)
+	connected := false
+
 	for {
 		unixConnect, err := net.DialUnix("unix", nil, getUnixSock2Adress())
 		if err != nil {
@@ -37,19 +157,33 @@ func InitHyprEvents() {
 			time.Sleep(5 * time.Second)
 			continue
 		}
-		defer unixConnect.Close()
 
+		// events may have been missed while disconnected,
+		// and a restarted Hyprland may be another version
+		reconnect := connected
+		connected = true
+		go func() {
+			DetectVersion()
+			if CacheStarted() {
+				ResyncCache()
+			}
+			if reconnect {
+				runReconnectHandlers()
+			}
+		}()
+
+		// read whole lines, a single read may end in the middle of an event
+		reader := bufio.NewReader(unixConnect)
 		for {
-			buffer := make([]byte, 10240)
-			unixNumber, err := unixConnect.Read(buffer)
+			line, err := reader.ReadString('\n')
 			if err != nil {
 				log.Printf("Error reading from Unix socket: %v. Reconnecting...", err)
+				unixConnect.Close()
 				break
 			}
 
-			events := splitEvent(string(buffer[:unixNumber]))
-			for _, event := range events {
-				go DispatchEvent(event)
+			for _, event := range splitEvent(line) {
+				queue.push(event)
 			}
 		}
 	}
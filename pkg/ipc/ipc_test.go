@@ -0,0 +1,119 @@
+package ipc_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+	"hypr-dock/pkg/ipc/ipctest"
+)
+
+// srv is shared by the tests, InitHyprEvents can't be stopped once it runs
+var srv *ipctest.Server
+
+func TestMain(m *testing.M) {
+	var err error
+	srv, err = ipctest.New()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := func() int {
+		defer srv.Close()
+
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		go ipc.InitHyprEvents()
+		if err := srv.WaitSubscribers(1, time.Second); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		return m.Run()
+	}()
+
+	os.Exit(code)
+}
+
+// restoreFixtures undoes the replies changed by the test
+func restoreFixtures(t *testing.T) {
+	t.Cleanup(func() {
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestHyprctlSocketMissing(t *testing.T) {
+	ipc.SetSocketDir(t.TempDir())
+	defer ipc.SetSocketDir(srv.Dir)
+
+	_, err := ipc.Hyprctl("j/clients")
+	if !errors.Is(err, ipc.ErrSocketMissing) {
+		t.Fatalf("got %v, want ErrSocketMissing", err)
+	}
+}
+
+func TestHyprctlPartialResponse(t *testing.T) {
+	restoreFixtures(t)
+
+	tests := map[string]string{
+		"truncated": `[{"address": "0x5a1b2c3d4e50", "class": "ki`,
+		"empty":     "",
+	}
+
+	for name, reply := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv.SetReply("j/clients", []byte(reply))
+
+			_, err := ipc.GetClients()
+			if !errors.Is(err, ipc.ErrPartialResponse) {
+				t.Fatalf("got %v, want ErrPartialResponse", err)
+			}
+		})
+	}
+}
+
+func TestHyprctlReplyError(t *testing.T) {
+	restoreFixtures(t)
+	srv.SetReply("dispatch nosuchdispatcher", []byte("Invalid dispatcher"))
+	srv.SetReply("j/clients", []byte("Not allowed"))
+
+	tests := []string{
+		"dispatch nosuchdispatcher",
+		"j/clients",
+		"j/nosuchrequest",
+	}
+
+	for _, request := range tests {
+		t.Run(request, func(t *testing.T) {
+			_, err := ipc.Hyprctl(request)
+
+			var replyErr *ipc.ReplyError
+			if !errors.As(err, &replyErr) {
+				t.Fatalf("got %v, want ReplyError", err)
+			}
+			if replyErr.Request != request {
+				t.Errorf("request %q, want %q", replyErr.Request, request)
+			}
+		})
+	}
+}
+
+func TestHyprctlJSON(t *testing.T) {
+	clients, err := ipc.GetClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) != 2 || clients[0].Class != "kitty" || clients[1].Class != "firefox" {
+		t.Fatalf("unexpected clients %+v", clients)
+	}
+}
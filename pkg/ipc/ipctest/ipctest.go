@@ -0,0 +1,341 @@
+// Package ipctest runs a stand-in for the Hyprland sockets, so that code using
+// pkg/ipc can be exercised without Hyprland:
+//
+//	srv, err := ipctest.New()
+//	if err != nil { ... }
+//	defer srv.Close()
+//
+//	srv.LoadDefaultFixtures()
+//	go ipc.InitHyprEvents()
+//	srv.WaitSubscribers(1, time.Second)
+//
+//	srv.SendEvent("openwindow", "5a1b2c3d4e70", "1", "foot", "foot")
+package ipctest
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"hypr-dock/pkg/ipc"
+)
+
+//go:embed fixtures/*.json
+var defaultFixtures embed.FS
+
+// fixture files and the requests they answer
+var fixtureRequests = map[string]string{
+	"clients.json":      "j/clients",
+	"monitors.json":     "j/monitors",
+	"workspaces.json":   "j/workspaces",
+	"layers.json":       "j/layers",
+	"activewindow.json": "j/activewindow",
+	"version.json":      "j/version",
+}
+
+type Server struct {
+	Dir string
+	// BatchSeparator joins the replies of a [[BATCH]] request,
+	// Hyprland releases differ in it. The replies run together by default.
+	BatchSeparator string
+
+	replies     map[string][]byte
+	requests    []string
+	subscribers []net.Conn
+
+	ctl    net.Listener
+	events net.Listener
+	mu     sync.Mutex
+}
+
+// New starts .socket.sock and .socket2.sock in a temporary directory
+// and points pkg/ipc to them. Until fixtures are loaded, the lists are empty.
+func New() (*Server, error) {
+	dir, err := os.MkdirTemp("", "hypr-ipctest-")
+	if err != nil {
+		return nil, err
+	}
+
+	ctl, err := net.Listen("unix", filepath.Join(dir, ".socket.sock"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	events, err := net.Listen("unix", filepath.Join(dir, ".socket2.sock"))
+	if err != nil {
+		ctl.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	s := &Server{
+		Dir:    dir,
+		ctl:    ctl,
+		events: events,
+		replies: map[string][]byte{
+			"j/clients":      []byte("[]"),
+			"j/monitors":     []byte("[]"),
+			"j/workspaces":   []byte("[]"),
+			"j/layers":       []byte("{}"),
+			"j/activewindow": []byte("{}"),
+		},
+	}
+
+	go s.serveCtl()
+	go s.serveEvents()
+
+	ipc.SetSocketDir(dir)
+	return s, nil
+}
+
+// Close stops both sockets, disconnects the subscribers and restores the pkg/ipc sockets
+func (s *Server) Close() error {
+	ipc.SetSocketDir("")
+
+	s.mu.Lock()
+	for _, conn := range s.subscribers {
+		conn.Close()
+	}
+	s.subscribers = nil
+	s.mu.Unlock()
+
+	err := errors.Join(s.ctl.Close(), s.events.Close())
+	os.RemoveAll(s.Dir)
+	return err
+}
+
+// SetReply answers the request with the reply as is
+func (s *Server) SetReply(request string, reply []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[request] = reply
+}
+
+// SetJSON answers the request with v encoded as JSON
+func (s *Server) SetJSON(request string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.SetReply(request, data)
+	return nil
+}
+
+func (s *Server) SetClients(clients []ipc.Client) error {
+	return s.SetJSON("j/clients", clients)
+}
+
+func (s *Server) SetMonitors(monitors []ipc.Monitor) error {
+	return s.SetJSON("j/monitors", monitors)
+}
+
+func (s *Server) SetWorkspaces(workspaces []ipc.Workspace) error {
+	return s.SetJSON("j/workspaces", workspaces)
+}
+
+// SetVersion answers j/version, e.g. "v0.40.0" to test the fallbacks of older releases
+func (s *Server) SetVersion(tag string) error {
+	return s.SetJSON("j/version", ipc.Version{Tag: tag})
+}
+
+// SetOption answers "j/getoption <name>"
+func (s *Server) SetOption(name string, v interface{}) error {
+	return s.SetJSON("j/getoption "+name, v)
+}
+
+// LoadFixtures reads clients.json, monitors.json, workspaces.json, layers.json,
+// activewindow.json, version.json and options.json from dir. Missing files are skipped.
+func (s *Server) LoadFixtures(dir string) error {
+	return s.loadFixtures(func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, name))
+	})
+}
+
+// LoadDefaultFixtures loads one monitor DP-1, workspaces 1 and 2,
+// kitty and firefox on workspace 1, the hypr-dock layer and general:gaps_out
+func (s *Server) LoadDefaultFixtures() error {
+	return s.loadFixtures(func(name string) ([]byte, error) {
+		return defaultFixtures.ReadFile("fixtures/" + name)
+	})
+}
+
+func (s *Server) loadFixtures(read func(name string) ([]byte, error)) error {
+	for file, request := range fixtureRequests {
+		data, err := read(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.SetReply(request, data)
+	}
+
+	data, err := read("options.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal(data, &options); err != nil {
+		return fmt.Errorf("options.json: %w", err)
+	}
+
+	for name, option := range options {
+		s.SetReply("j/getoption "+name, option)
+	}
+	return nil
+}
+
+// Requests returns every request received on .socket.sock, in order
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// SendEvent writes "name>>data[,data...]" to every subscriber of .socket2.sock
+func (s *Server) SendEvent(name string, data ...string) error {
+	return s.SendRaw(name + ">>" + strings.Join(data, ","))
+}
+
+// SendRaw writes the lines as they are, e.g. a script of recorded events
+func (s *Server) SendRaw(lines ...string) error {
+	var payload strings.Builder
+	for _, line := range lines {
+		payload.WriteString(line)
+		payload.WriteString("\n")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, conn := range s.subscribers {
+		if _, err := conn.Write([]byte(payload.String())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WaitSubscribers waits until n clients are connected to .socket2.sock
+func (s *Server) WaitSubscribers(n int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		count := len(s.subscribers)
+		s.mu.Unlock()
+
+		if count >= n {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return fmt.Errorf("%d event subscribers expected", n)
+}
+
+// Disconnect closes the event connections, like a Hyprland restart
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.subscribers {
+		conn.Close()
+	}
+	s.subscribers = nil
+}
+
+func (s *Server) serveCtl() {
+	for {
+		conn, err := s.ctl.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) serveEvents() {
+	for {
+		conn, err := s.events.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.subscribers = append(s.subscribers, conn)
+		s.mu.Unlock()
+	}
+}
+
+// handle answers one request and closes the connection, as Hyprland does
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	// requests are not terminated, read what the client has written
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return
+	}
+
+	request := strings.TrimSpace(string(buf[:n]))
+	conn.Write(s.reply(request))
+}
+
+func (s *Server) reply(request string) []byte {
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	s.mu.Unlock()
+
+	if batch, ok := strings.CutPrefix(request, "[[BATCH]]"); ok {
+		var replies []string
+		for _, cmd := range strings.Split(batch, ";") {
+			cmd = strings.TrimSpace(cmd)
+			if cmd != "" {
+				replies = append(replies, string(s.single(cmd)))
+			}
+		}
+		s.mu.Lock()
+		separator := s.BatchSeparator
+		s.mu.Unlock()
+		return []byte(strings.Join(replies, separator))
+	}
+
+	return s.single(request)
+}
+
+func (s *Server) single(request string) []byte {
+	s.mu.Lock()
+	reply, exist := s.replies[request]
+	s.mu.Unlock()
+
+	if exist {
+		return reply
+	}
+
+	if strings.HasPrefix(request, "dispatch ") || strings.HasPrefix(request, "keyword ") {
+		return []byte("ok")
+	}
+
+	return []byte("unknown request")
+}
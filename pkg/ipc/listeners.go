@@ -1,8 +1,9 @@
 package ipc
 
 import (
-	"strings"
+	"log"
 	"sync"
+	"time"
 )
 
 type EventListener struct {
@@ -10,6 +11,8 @@ type EventListener struct {
 	Handler func(string)
 	ID      int
 	running bool
+
+	typed func(Event)
 }
 
 type eventManager struct {
@@ -33,34 +36,81 @@ func getEventManager() *eventManager {
 	return eventManagerInstance
 }
 
+// AddEventListener calls the handler with the raw "name>>data" line
+// of every event whose name is exactly event
 func AddEventListener(event string, handler func(string), running bool) *EventListener {
-	em := getEventManager()
-	em.mu.Lock()
-	defer em.mu.Unlock()
-
-	listener := &EventListener{
+	return addListener(&EventListener{
 		Event:   event,
 		Handler: handler,
-		ID:      em.listenerCounter,
 		running: running,
-	}
+	})
+}
 
+// Subscribe calls the handler with the parsed events of type E:
+//
+//	ipc.Subscribe(func(e ipc.OpenWindow) { ... }, true)
+func Subscribe[E Event](handler func(E), running bool) *EventListener {
+	var zero E
+
+	return addListener(&EventListener{
+		Event: zero.EventName(),
+		typed: func(event Event) {
+			if e, ok := event.(E); ok {
+				handler(e)
+			}
+		},
+		running: running,
+	})
+}
+
+func addListener(listener *EventListener) *EventListener {
+	em := getEventManager()
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	listener.ID = em.listenerCounter
 	em.eventListeners = append(em.eventListeners, listener)
 	em.listenerCounter++
 	return listener
 }
 
-func DispatchEvent(event string) {
-	// log.Printf("IPC Debug: event: %s", event)
+// DispatchEvent synchronously calls the listeners of the event line
+func DispatchEvent(line string) {
+	event, err := ParseEvent(line)
+	if err != nil {
+		queue.invalid()
+		log.Printf("IPC: failed to parse event %q: %v", line, err)
+		return
+	}
 
+	deliver(event, line)
+}
+
+// deliver calls the listeners of an already parsed event
+func deliver(event Event, line string) {
+	name := event.EventName()
+
+	// copy the listeners, so that handlers can add or remove listeners
 	em := getEventManager()
 	em.mu.Lock()
-	defer em.mu.Unlock()
-
+	var listeners []*EventListener
 	for _, listener := range em.eventListeners {
-		if strings.Contains(event, listener.Event) && listener.running {
-			listener.Handler(event)
+		if listener.Event == name && listener.running {
+			listeners = append(listeners, listener)
+		}
+	}
+	em.mu.Unlock()
+
+	for _, listener := range listeners {
+		start := time.Now()
+
+		if listener.typed != nil {
+			listener.typed(event)
+		} else {
+			listener.Handler(line)
 		}
+
+		queue.handled(name, time.Since(start))
 	}
 }
 
@@ -0,0 +1,158 @@
+package ipc
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// events read from the socket and not yet handled
+	eventQueueSize = 1024
+	// how long the socket reader waits for a full queue before dropping an event
+	eventQueueWait = time.Second
+	// handlers running longer than this are counted and logged as slow
+	slowHandler = 100 * time.Millisecond
+)
+
+type EventStats struct {
+	Received       uint64
+	Delivered      uint64
+	Dropped        uint64
+	Invalid        uint64
+	SlowHandlers   uint64
+	SlowestHandler time.Duration
+	SlowestEvent   string
+	Queued         int
+}
+
+// eventQueue delivers the events one by one in the order of the socket,
+// so that e.g. openwindow is always handled before closewindow of the same window
+type eventQueue struct {
+	events chan string
+	tasks  chan func()
+	stats  EventStats
+	// an event was dropped, the state is resynced once the queue is empty
+	lost bool
+	mu   sync.Mutex
+	once sync.Once
+}
+
+var queue = &eventQueue{
+	events: make(chan string, eventQueueSize),
+	tasks:  make(chan func(), 16),
+}
+
+// GetEventStats returns the delivery counters of the Hyprland events
+func GetEventStats() EventStats {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	stats := queue.stats
+	stats.Queued = len(queue.events)
+	return stats
+}
+
+func (q *eventQueue) start() {
+	q.once.Do(func() {
+		go func() {
+			for {
+				select {
+				case line := <-q.events:
+					DispatchEvent(line)
+					if len(q.events) == 0 && q.takeLost() {
+						go resyncLost()
+					}
+				case task := <-q.tasks:
+					task()
+				}
+			}
+		}()
+	})
+}
+
+// run executes the task between two events, never concurrently with a handler
+func (q *eventQueue) run(task func()) {
+	q.start()
+	q.tasks <- task
+}
+
+// runWait is run that returns after the task is done.
+// It must not be called from an event handler.
+func (q *eventQueue) runWait(task func()) {
+	done := make(chan struct{})
+	q.run(func() {
+		task()
+		close(done)
+	})
+	<-done
+}
+
+// push blocks the reader while the queue is full, but not longer than eventQueueWait
+func (q *eventQueue) push(line string) {
+	q.mu.Lock()
+	q.stats.Received++
+	q.mu.Unlock()
+
+	select {
+	case q.events <- line:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(eventQueueWait)
+	defer timer.Stop()
+
+	select {
+	case q.events <- line:
+	case <-timer.C:
+		q.mu.Lock()
+		q.stats.Dropped++
+		q.lost = true
+		q.mu.Unlock()
+		log.Printf("IPC: event queue is full, dropped %q", line)
+	}
+}
+
+// takeLost tells if an event was dropped since the last call
+func (q *eventQueue) takeLost() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lost := q.lost
+	q.lost = false
+	return lost
+}
+
+// resyncLost reloads the state the dropped events would have changed
+// and lets the OnReconnect handlers catch up with it
+func resyncLost() {
+	log.Printf("IPC: events were dropped, resyncing")
+	if CacheStarted() {
+		ResyncCache()
+	}
+	runReconnectHandlers()
+}
+
+func (q *eventQueue) handled(name string, elapsed time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stats.Delivered++
+
+	if elapsed > q.stats.SlowestHandler {
+		q.stats.SlowestHandler = elapsed
+		q.stats.SlowestEvent = name
+	}
+
+	if elapsed >= slowHandler {
+		q.stats.SlowHandlers++
+		log.Printf("IPC: slow handler for %q: %s", name, elapsed)
+	}
+}
+
+func (q *eventQueue) invalid() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stats.Invalid++
+}
@@ -0,0 +1,102 @@
+package ipc_test
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+)
+
+func TestQueueOrder(t *testing.T) {
+	var got []string
+	listener := ipc.AddEventListener("testorder", func(line string) {
+		got = append(got, strings.TrimPrefix(line, "testorder>>"))
+	}, true)
+	defer listener.Remove()
+
+	var lines, want []string
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("testorder>>%d", i))
+		want = append(want, strconv.Itoa(i))
+	}
+	if err := srv.SendRaw(lines...); err != nil {
+		t.Fatal(err)
+	}
+	sendEvent(t, "configreloaded")
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("delivered out of order: %v", got)
+	}
+}
+
+func TestQueueInvalidEvent(t *testing.T) {
+	before := ipc.GetEventStats().Invalid
+	sendEvent(t, "movewindowv2", "5a1b2c3d4e50", "three", "3")
+
+	if invalid := ipc.GetEventStats().Invalid; invalid != before+1 {
+		t.Fatalf("invalid events %d, want %d", invalid, before+1)
+	}
+}
+
+func TestQueueDropResyncs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("fills the event queue and waits for the drop")
+	}
+
+	resynced := make(chan struct{}, 1)
+	ipc.OnReconnect(func() {
+		select {
+		case resynced <- struct{}{}:
+		default:
+		}
+	})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	listener := ipc.AddEventListener("testblock", func(string) {
+		close(started)
+		<-release
+	}, true)
+	defer listener.Remove()
+
+	if err := srv.SendEvent("testblock"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// the 1024 queued events fill the queue, the next one is dropped
+	before := ipc.GetEventStats().Dropped
+	var lines []string
+	for i := range 1025 {
+		lines = append(lines, fmt.Sprintf("testfill>>%d", i))
+	}
+	if err := srv.SendRaw(lines...); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for ipc.GetEventStats().Dropped == before {
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatal("no event dropped from a full queue")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// drain stale signals, e.g. of the reconnect test
+	select {
+	case <-resynced:
+	default:
+	}
+	close(release)
+
+	select {
+	case <-resynced:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no resync after the queue drained")
+	}
+}
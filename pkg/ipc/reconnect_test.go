@@ -0,0 +1,48 @@
+package ipc_test
+
+import (
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+)
+
+func TestCacheResyncsAfterReconnect(t *testing.T) {
+	startCache(t)
+	restoreFixtures(t)
+
+	reconnected := make(chan struct{}, 1)
+	ipc.OnReconnect(func() {
+		select {
+		case reconnected <- struct{}{}:
+		default:
+		}
+	})
+
+	// firefox closes while the dock is disconnected
+	kittyOnly := []ipc.Client{cachedClient(t, kitty)}
+	if err := srv.SetClients(kittyOnly); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Disconnect()
+	if err := srv.WaitSubscribers(1, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-reconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reconnect handlers were not called")
+	}
+
+	if _, exist := ipc.CachedClient(firefox); exist {
+		t.Fatal("window closed while disconnected is still in the cache")
+	}
+
+	// the new connection delivers events
+	sendEvent(t, "windowtitlev2", "5a1b2c3d4e50", "htop")
+	if title := cachedClient(t, kitty).Title; title != "htop" {
+		t.Fatalf("title %q after reconnect", title)
+	}
+}
@@ -0,0 +1,83 @@
+package ipc_test
+
+import (
+	"testing"
+	"time"
+
+	"hypr-dock/pkg/ipc"
+)
+
+const (
+	kitty   = "0x5a1b2c3d4e50"
+	firefox = "0x5a1b2c3d4f60"
+	foot    = "0x5a1b2c3d5070"
+)
+
+// startCache loads the cache from the fixtures and drops it after the test
+func startCache(t *testing.T) {
+	t.Helper()
+
+	ipc.SetResyncDelay(time.Hour)
+	if err := ipc.StartCache(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ipc.ResetCache)
+}
+
+// sendEvent writes the event and waits until the listeners have handled it
+func sendEvent(t *testing.T, name string, data ...string) {
+	t.Helper()
+
+	handled := make(chan struct{}, 1)
+	listener := ipc.Subscribe(func(e ipc.ConfigReloaded) {
+		handled <- struct{}{}
+	}, true)
+	defer listener.Remove()
+
+	if err := srv.SendEvent(name, data...); err != nil {
+		t.Fatal(err)
+	}
+	// the events are delivered in order, the marker comes after the event
+	if err := srv.SendEvent("configreloaded"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s was not handled", name)
+	}
+}
+
+func cachedClient(t *testing.T, address string) ipc.Client {
+	t.Helper()
+
+	client, exist := ipc.CachedClient(address)
+	if !exist {
+		t.Fatalf("%s is not in the cache", address)
+	}
+	return client
+}
+
+func TestResetCache(t *testing.T) {
+	startCache(t)
+	ipc.ResetCache()
+
+	if ipc.CacheStarted() {
+		t.Fatal("cache still started after reset")
+	}
+	if clients := ipc.CachedClients(); len(clients) != 0 {
+		t.Fatalf("got %d clients after reset", len(clients))
+	}
+
+	// the listeners of the cache are gone
+	sendEvent(t, "openwindow", "5a1b2c3d5070", "1", "foot", "~")
+	if _, exist := ipc.CachedClient(foot); exist {
+		t.Fatal("reset cache applied an event")
+	}
+
+	startCache(t)
+	if clients := ipc.CachedClients(); len(clients) != 2 {
+		t.Fatalf("got %d clients after restart, want 2", len(clients))
+	}
+}
@@ -28,6 +28,11 @@ type Monitor struct {
 		Name string `json:"name"`
 	} `json:"activeWorkspace"`
 
+	SpecialWorkspace struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"specialWorkspace"`
+
 	Reserved   []int   `json:"reserved"`
 	Scale      float64 `json:"scale"`
 	Transform  int     `json:"transform"`
@@ -66,3 +71,16 @@ type Client struct {
 	FocusHistoryID   int           `json:"focusHistoryID"`
 	InhibitingIdle   bool          `json:"inhibitingIdle"`
 }
+
+type Layer struct {
+	Address   string `json:"address"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	W         int    `json:"w"`
+	H         int    `json:"h"`
+	Namespace string `json:"namespace"`
+	Pid       int    `json:"pid"`
+
+	Monitor string `json:"-"`
+	Level   string `json:"-"`
+}
@@ -7,9 +7,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 func SearchClientByAddress(address string) (Client, error) {
+	if CacheStarted() {
+		if client, ok := CachedClient(address); ok {
+			return client, nil
+		}
+		return Client{}, errors.New("Client non found by address: " + address)
+	}
+
 	clients, err := GetClients()
 	if err != nil {
 		log.Println(err)
@@ -26,7 +34,26 @@ func SearchClientByAddress(address string) (Client, error) {
 	return Client{}, err
 }
 
+// SpecialWorkspaceName returns "term" for "special:term" and "special" for "special:special",
+// ok is false for a regular workspace
+func SpecialWorkspaceName(workspace string) (name string, ok bool) {
+	return strings.CutPrefix(workspace, "special:")
+}
+
+// InSpecialWorkspace tells if the window is in a scratchpad
+func (c Client) InSpecialWorkspace() bool {
+	_, ok := SpecialWorkspaceName(c.Workspace.Name)
+	return ok
+}
+
 func SearchMonitorByName(name string) (*Monitor, error) {
+	if CacheStarted() {
+		if monitor, ok := CachedMonitor(name); ok {
+			return &monitor, nil
+		}
+		return nil, errors.New("Monitor not found by name: " + name)
+	}
+
 	monitors, err := GetMonitors()
 	if err != nil {
 		return nil, err
@@ -46,17 +73,39 @@ func getHyprPathes() (XDGRuntimeDirHypr string, HIS string) {
 	return XDGRuntimeDirHypr, HIS
 }
 
-func getUnixSockAdress() (unixSockAdress string) {
-	XDGRuntimeDirHypr, HIS := getHyprPathes()
+var (
+	socketDir   string
+	socketDirMu sync.Mutex
+)
 
-	return filepath.Join(XDGRuntimeDirHypr, HIS, ".socket.sock")
+// SetSocketDir makes the package use .socket.sock and .socket2.sock from dir
+// instead of the sockets of the running Hyprland instance (see ipctest).
+// An empty dir restores the default.
+func SetSocketDir(dir string) {
+	socketDirMu.Lock()
+	defer socketDirMu.Unlock()
+	socketDir = dir
 }
 
-func getUnixSock2Adress() (unixSock2Adress *net.UnixAddr) {
+func getSocketDir() string {
+	socketDirMu.Lock()
+	defer socketDirMu.Unlock()
+
+	if socketDir != "" {
+		return socketDir
+	}
+
 	XDGRuntimeDirHypr, HIS := getHyprPathes()
+	return filepath.Join(XDGRuntimeDirHypr, HIS)
+}
 
+func getUnixSockAdress() (unixSockAdress string) {
+	return filepath.Join(getSocketDir(), ".socket.sock")
+}
+
+func getUnixSock2Adress() (unixSock2Adress *net.UnixAddr) {
 	return &net.UnixAddr{
-		Name: filepath.Join(XDGRuntimeDirHypr, HIS, ".socket2.sock"),
+		Name: filepath.Join(getSocketDir(), ".socket2.sock"),
 		Net:  "unix",
 	}
 }
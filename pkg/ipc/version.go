@@ -0,0 +1,207 @@
+package ipc
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the reply of j/version.
+// Releases before 0.41 have no "version" field, only the tag.
+type Version struct {
+	Branch  string   `json:"branch"`
+	Commit  string   `json:"commit"`
+	Version string   `json:"version"`
+	Dirty   bool     `json:"dirty"`
+	Tag     string   `json:"tag"`
+	Flags   []string `json:"flags"`
+}
+
+// Capabilities tells which features of newer Hyprland releases are available
+type Capabilities struct {
+	// windowtitlev2 event, added in 0.42
+	WindowTitleV2 bool
+	// focusHistoryID in j/clients, added in 0.35
+	FocusHistoryID bool
+}
+
+var allCapabilities = Capabilities{
+	WindowTitleV2:  true,
+	FocusHistoryID: true,
+}
+
+type versionState struct {
+	version      Version
+	capabilities Capabilities
+	detected     bool
+	fallbacks    sync.Once
+	mu           sync.RWMutex
+}
+
+var hyprVersion = &versionState{capabilities: allCapabilities}
+
+func GetVersion() (Version, error) {
+	var version Version
+	err := hyprctlJSON("j/version", &version)
+	return version, err
+}
+
+// Number returns major, minor and patch, ok is false for builds without a release tag
+func (v Version) Number() (major, minor, patch int, ok bool) {
+	s := v.Version
+	if s == "" {
+		s = v.Tag
+	}
+
+	// tags look like "v0.34.0" or "v0.34.0-123-gabcdef"
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		numbers[i] = n
+	}
+
+	return numbers[0], numbers[1], numbers[2], true
+}
+
+// AtLeast compares the release with major.minor.patch,
+// a build of unknown version is assumed to be recent
+func (v Version) AtLeast(major, minor, patch int) bool {
+	vMajor, vMinor, vPatch, ok := v.Number()
+	if !ok {
+		return true
+	}
+
+	if vMajor != major {
+		return vMajor > major
+	}
+	if vMinor != minor {
+		return vMinor > minor
+	}
+	return vPatch >= patch
+}
+
+func (v Version) String() string {
+	if major, minor, patch, ok := v.Number(); ok {
+		return fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	}
+	if v.Commit != "" {
+		return "commit " + v.Commit
+	}
+	return "unknown"
+}
+
+func (v Version) capabilities() Capabilities {
+	return Capabilities{
+		WindowTitleV2:  v.AtLeast(0, 42, 0),
+		FocusHistoryID: v.AtLeast(0, 35, 0),
+	}
+}
+
+// DetectVersion queries j/version and sets the capabilities.
+// If the query fails, every feature is assumed to be available.
+func DetectVersion() (Version, error) {
+	version, err := GetVersion()
+	if err != nil {
+		log.Printf("IPC: failed to detect the Hyprland version, assuming a recent one: %v", err)
+		return version, err
+	}
+
+	capabilities := version.capabilities()
+
+	hyprVersion.mu.Lock()
+	changed := !hyprVersion.detected || hyprVersion.capabilities != capabilities || hyprVersion.version.String() != version.String()
+	hyprVersion.version = version
+	hyprVersion.capabilities = capabilities
+	hyprVersion.detected = true
+	hyprVersion.mu.Unlock()
+
+	if changed {
+		log.Printf("IPC: Hyprland %s", version)
+		if !capabilities.WindowTitleV2 {
+			log.Printf("IPC: windowtitlev2 is not available, titles are read from j/clients on windowtitle")
+		}
+		if !capabilities.FocusHistoryID {
+			log.Printf("IPC: focusHistoryID is not available, the focus order is tracked from activewindowv2")
+		}
+	}
+
+	return version, nil
+}
+
+// HyprlandVersion returns the version found by DetectVersion
+func HyprlandVersion() Version {
+	hyprVersion.mu.RLock()
+	defer hyprVersion.mu.RUnlock()
+	return hyprVersion.version
+}
+
+// GetCapabilities returns the features of the running Hyprland,
+// all of them until DetectVersion has succeeded
+func GetCapabilities() Capabilities {
+	hyprVersion.mu.RLock()
+	defer hyprVersion.mu.RUnlock()
+	return hyprVersion.capabilities
+}
+
+// subscribeFallbacks emulates the missing events, so that listeners
+// only need to subscribe to the newest ones
+func subscribeFallbacks() {
+	Subscribe(func(e WindowTitle) {
+		if GetCapabilities().WindowTitleV2 {
+			return
+		}
+
+		title, ok := windowTitle(e.Address)
+		if !ok {
+			return
+		}
+
+		event := WindowTitleV2{Address: e.Address, Title: title}
+		line := event.EventName() + ">>" + strings.TrimPrefix(e.Address, "0x") + "," + title
+		deliver(event, line)
+	}, true)
+}
+
+// windowTitle reads the title of a window, windowtitle only carries the address.
+// With the cache running, unknown windows are skipped and the active one
+// is read alone from j/activewindow.
+func windowTitle(address string) (string, bool) {
+	if CacheStarted() {
+		if _, ok := CachedClient(address); !ok {
+			return "", false
+		}
+
+		if active, ok := CachedActiveWindow(); ok && active.Address == address {
+			client, err := GetActiveWindow()
+			if err == nil && client.Address == address {
+				return client.Title, true
+			}
+		}
+	}
+
+	clients, err := GetClients()
+	if err != nil {
+		log.Printf("IPC: failed to get the title of %s: %v", address, err)
+		return "", false
+	}
+
+	for _, client := range clients {
+		if client.Address == address {
+			return client.Title, true
+		}
+	}
+	return "", false
+}
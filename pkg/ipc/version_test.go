@@ -0,0 +1,102 @@
+package ipc_test
+
+import (
+	"testing"
+
+	"hypr-dock/pkg/ipc"
+)
+
+// olderRelease makes the server answer as Hyprland 0.40, without windowtitlev2
+func olderRelease(t *testing.T) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		if err := srv.LoadDefaultFixtures(); err != nil {
+			t.Fatal(err)
+		}
+		ipc.DetectVersion()
+	})
+
+	if err := srv.SetVersion("v0.40.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ipc.DetectVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if ipc.GetCapabilities().WindowTitleV2 {
+		t.Fatal("0.40 reported with windowtitlev2")
+	}
+}
+
+// collectTitles returns the windowtitlev2 events of the test
+func collectTitles(t *testing.T) <-chan ipc.WindowTitleV2 {
+	titles := make(chan ipc.WindowTitleV2, 4)
+	listener := ipc.Subscribe(func(e ipc.WindowTitleV2) {
+		titles <- e
+	}, true)
+	t.Cleanup(listener.Remove)
+	return titles
+}
+
+func TestWindowTitleFallback(t *testing.T) {
+	olderRelease(t)
+	titles := collectTitles(t)
+
+	clients, err := ipc.GetClients()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients[1].Title = "Mozilla Firefox"
+	if err := srv.SetClients(clients); err != nil {
+		t.Fatal(err)
+	}
+
+	sendEvent(t, "windowtitle", "5a1b2c3d4f60")
+
+	select {
+	case e := <-titles:
+		if e.Address != firefox || e.Title != "Mozilla Firefox" {
+			t.Fatalf("got %+v", e)
+		}
+	default:
+		t.Fatal("no windowtitlev2 for windowtitle")
+	}
+}
+
+func TestWindowTitleFallbackCached(t *testing.T) {
+	olderRelease(t)
+	startCache(t)
+	titles := collectTitles(t)
+
+	// the active window is read alone, j/clients keeps the old title
+	active, err := ipc.GetActiveWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	active.Title = "vim"
+	if err := srv.SetJSON("j/activewindow", active); err != nil {
+		t.Fatal(err)
+	}
+
+	sendEvent(t, "windowtitle", "5a1b2c3d4e50")
+	sendEvent(t, "windowtitle", "5a1b2c3d5070")
+
+	select {
+	case e := <-titles:
+		if e.Address != kitty || e.Title != "vim" {
+			t.Fatalf("got %+v", e)
+		}
+	default:
+		t.Fatal("no windowtitlev2 for windowtitle")
+	}
+	if client := cachedClient(t, kitty); client.Title != "vim" {
+		t.Errorf("cached title %q", client.Title)
+	}
+
+	// foot is not in the cache
+	select {
+	case e := <-titles:
+		t.Fatalf("got %+v for an unknown window", e)
+	default:
+	}
+}